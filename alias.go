@@ -0,0 +1,229 @@
+package elastic
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/grpc-boot/base"
+)
+
+const (
+	aliasAdd         = `add`
+	aliasRemove      = `remove`
+	aliasRemoveIndex = `remove_index`
+)
+
+// Alias
+// link: https://www.elastic.co/guide/en/elasticsearch/reference/current/aliases.html
+type Alias struct {
+	n             string
+	Filter        base.JsonParam `json:"filter,omitempty"`
+	Routing       string         `json:"routing,omitempty"`
+	IndexRouting  string         `json:"index_routing,omitempty"`
+	SearchRouting string         `json:"search_routing,omitempty"`
+	IsWriteIndex  *bool          `json:"is_write_index,omitempty"`
+	IsHidden      *bool          `json:"is_hidden,omitempty"`
+}
+
+func NewAlias(name string) *Alias {
+	return &Alias{n: name}
+}
+
+func (a *Alias) Name() string {
+	return a.n
+}
+
+// WithFilter filtered alias, only documents matching condition are visible through alias
+func (a *Alias) WithFilter(condition Condition) *Alias {
+	return a.WithFilterString(condition.Build())
+}
+
+func (a *Alias) WithFilterString(where string) *Alias {
+	a.Filter = base.JsonParam{"query_string": base.JsonParam{"query": where}}
+	return a
+}
+
+// WithRouting routing of both index and search operations
+func (a *Alias) WithRouting(routing string) *Alias {
+	a.Routing = routing
+	return a
+}
+
+func (a *Alias) WithIndexRouting(routing string) *Alias {
+	a.IndexRouting = routing
+	return a
+}
+
+func (a *Alias) WithSearchRouting(routing string) *Alias {
+	a.SearchRouting = routing
+	return a
+}
+
+func (a *Alias) WithWriteIndex(isWriteIndex bool) *Alias {
+	a.IsWriteIndex = &isWriteIndex
+	return a
+}
+
+func (a *Alias) WithHidden(isHidden bool) *Alias {
+	a.IsHidden = &isHidden
+	return a
+}
+
+func (a *Alias) Marshal() []byte {
+	data, _ := base.JsonMarshal(a)
+	return data
+}
+
+func aliasesMap(aliases []*Alias) map[string]*Alias {
+	m := make(map[string]*Alias, len(aliases))
+	for _, alias := range aliases {
+		m[alias.n] = alias
+	}
+
+	return m
+}
+
+// AliasAction one action of atomic alias update
+type AliasAction struct {
+	action string
+	index  string
+	alias  *Alias
+}
+
+func AddAlias(index string, alias *Alias) AliasAction {
+	return AliasAction{action: aliasAdd, index: index, alias: alias}
+}
+
+func RemoveAlias(index string, alias string) AliasAction {
+	return AliasAction{action: aliasRemove, index: index, alias: NewAlias(alias)}
+}
+
+// RemoveIndex delete index in the same atomic update, usually after adding its alias to another index
+func RemoveIndex(index string) AliasAction {
+	return AliasAction{action: aliasRemoveIndex, index: index}
+}
+
+func (aa AliasAction) MarshalJSON() ([]byte, error) {
+	body := base.JsonParam{"index": aa.index}
+
+	if aa.alias != nil {
+		if aa.action == aliasAdd {
+			if err := base.JsonUnmarshal(aa.alias.Marshal(), &body); err != nil {
+				return nil, err
+			}
+		}
+		body["alias"] = aa.alias.n
+	}
+
+	return base.JsonMarshal(base.JsonParam{aa.action: body})
+}
+
+// AliasesUpdate apply actions atomically
+// link: https://www.elastic.co/guide/en/elasticsearch/reference/current/indices-aliases.html
+func (c *Connection) AliasesUpdate(timeout time.Duration, actions ...AliasAction) (ok bool, err error) {
+	param, err := base.JsonMarshal(map[string][]AliasAction{"actions": actions})
+	if err != nil {
+		return
+	}
+
+	resp, err := c.Post(timeout, "/_aliases", base.Bytes2String(param))
+	if err != nil {
+		return
+	}
+
+	if !resp.IsOk() {
+		return false, resp.Error()
+	}
+
+	return true, nil
+}
+
+// AliasCreate
+// link: https://www.elastic.co/guide/en/elasticsearch/reference/current/indices-add-alias.html
+func (c *Connection) AliasCreate(timeout time.Duration, index string, alias *Alias) (ok bool, err error) {
+	resp, err := c.Put(timeout, "/"+index+"/_alias/"+alias.n, base.Bytes2String(alias.Marshal()))
+	if err != nil {
+		return
+	}
+
+	if !resp.IsOk() {
+		return false, resp.Error()
+	}
+
+	return true, nil
+}
+
+// AliasDelete
+// link: https://www.elastic.co/guide/en/elasticsearch/reference/current/indices-delete-alias.html
+func (c *Connection) AliasDelete(timeout time.Duration, index string, alias string) (ok bool, err error) {
+	resp, err := c.Delete(timeout, "/"+index+"/_alias/"+alias, "")
+	if err != nil {
+		return
+	}
+
+	if !resp.IsOk() {
+		return false, resp.Error()
+	}
+
+	return true, nil
+}
+
+// Aliases aliases of index, index can be wildcard pattern or comma-separated list
+// link: https://www.elastic.co/guide/en/elasticsearch/reference/current/indices-get-alias.html
+func (c *Connection) Aliases(timeout time.Duration, index string) (map[string][]*Alias, error) {
+	var result map[string]struct {
+		Aliases map[string]*Alias `json:"aliases"`
+	}
+
+	resp, err := c.decode(timeout, http.MethodGet, "/"+index+"/_alias", "", &result)
+	if err != nil {
+		return nil, err
+	}
+
+	if !resp.IsOk() {
+		return nil, resp.Error()
+	}
+
+	aliases := make(map[string][]*Alias, len(result))
+	for name, item := range result {
+		list := make([]*Alias, 0, len(item.Aliases))
+		for aliasName, alias := range item.Aliases {
+			alias.n = aliasName
+			list = append(list, alias)
+		}
+
+		sort.Slice(list, func(i, j int) bool {
+			return list[i].n < list[j].n
+		})
+		aliases[name] = list
+	}
+
+	return aliases, nil
+}
+
+// AliasIndices indices which alias points to, empty when alias not exists
+func (c *Connection) AliasIndices(timeout time.Duration, alias string) ([]string, error) {
+	var result map[string]interface{}
+
+	resp, err := c.decode(timeout, http.MethodGet, "/_alias/"+alias, "", &result)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Is(http.StatusNotFound) {
+		return nil, nil
+	}
+
+	if !resp.IsOk() {
+		return nil, resp.Error()
+	}
+
+	indices := make([]string, 0, len(result))
+	for index := range result {
+		indices = append(indices, index)
+	}
+
+	sort.Strings(indices)
+	return indices, nil
+}
@@ -0,0 +1,79 @@
+package elastic
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"sync"
+)
+
+const (
+	maxPooledBufferSize = 4 << 20
+)
+
+var (
+	ErrResponseTooLarge = errors.New("response body too large")
+
+	bufferPool = sync.Pool{
+		New: func() interface{} {
+			return bytes.NewBuffer(make([]byte, 0, 4096))
+		},
+	}
+)
+
+// readBody 借助池化缓冲读取响应，只为结果分配一次精确大小的内存
+func (c *Connection) readBody(body io.Reader) ([]byte, error) {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	defer func() {
+		if buf.Cap() <= maxPooledBufferSize {
+			buf.Reset()
+			bufferPool.Put(buf)
+		}
+	}()
+
+	if _, err := buf.ReadFrom(c.limitBody(body)); err != nil {
+		return nil, err
+	}
+
+	data := make([]byte, buf.Len())
+	copy(data, buf.Bytes())
+
+	return data, nil
+}
+
+func (c *Connection) limitBody(body io.Reader) io.Reader {
+	if c.maxBytes < 1 {
+		return body
+	}
+
+	return &limitedReader{reader: body, remaining: c.maxBytes}
+}
+
+func exceeded(body io.Reader) bool {
+	lr, ok := body.(*limitedReader)
+	return ok && lr.remaining < 0
+}
+
+type limitedReader struct {
+	reader    io.Reader
+	remaining int64
+}
+
+func (lr *limitedReader) Read(p []byte) (n int, err error) {
+	if lr.remaining < 0 {
+		return 0, ErrResponseTooLarge
+	}
+
+	// 多读一个字节用于判断是否超限
+	if int64(len(p)) > lr.remaining+1 {
+		p = p[:lr.remaining+1]
+	}
+
+	n, err = lr.reader.Read(p)
+	lr.remaining -= int64(n)
+	if lr.remaining < 0 {
+		return n, ErrResponseTooLarge
+	}
+
+	return n, err
+}
@@ -0,0 +1,218 @@
+package elastic
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const (
+	BreakerClosed BreakerState = iota
+	BreakerOpen
+	BreakerHalfOpen
+)
+
+var (
+	ErrCircuitOpen = errors.New("circuit breaker is open")
+)
+
+type BreakerState uint8
+
+func (bs BreakerState) String() string {
+	switch bs {
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	}
+
+	return "closed"
+}
+
+// BreakerStateHandler receives circuit breaker state changes of node
+type BreakerStateHandler func(node string, from, to BreakerState)
+
+// CircuitOpenError returned without sending request while circuit breaker is open,
+// errors.Is(err, ErrCircuitOpen) reports true for it
+type CircuitOpenError struct {
+	Node       string
+	RetryAfter time.Duration
+}
+
+func (coe *CircuitOpenError) Error() string {
+	return ErrCircuitOpen.Error() + " node:" + coe.Node + " retry after:" + coe.RetryAfter.String()
+}
+
+func (coe *CircuitOpenError) Is(target error) bool {
+	return target == ErrCircuitOpen
+}
+
+type breaker struct {
+	mutex sync.Mutex
+
+	node             string
+	errorRate        float64
+	slow             time.Duration
+	minRequests      int
+	window           time.Duration
+	openDuration     time.Duration
+	halfOpenRequests int
+	handler          BreakerStateHandler
+
+	state       BreakerState
+	generation  uint64
+	windowStart time.Time
+	total       int
+	failures    int
+	openedAt    time.Time
+	probes      int
+	successes   int
+}
+
+func newBreaker(option *Option) *breaker {
+	if option.BreakerErrorRate <= 0 {
+		return nil
+	}
+
+	node := option.BaseUrl
+	if u, err := url.Parse(option.BaseUrl); err == nil && u.Host != "" {
+		node = u.Host
+	}
+
+	return &breaker{
+		node:             node,
+		errorRate:        option.BreakerErrorRate,
+		slow:             time.Duration(option.BreakerSlowMillisecond) * time.Millisecond,
+		minRequests:      option.BreakerMinRequests,
+		window:           time.Duration(option.BreakerWindowSecond) * time.Second,
+		openDuration:     time.Duration(option.BreakerOpenSecond) * time.Second,
+		halfOpenRequests: option.BreakerHalfOpenRequests,
+		handler:          option.BreakerStateHandler,
+		windowStart:      time.Now(),
+	}
+}
+
+// allow 判断请求是否可以发送，半开状态下只放行有限的探测请求，
+// 返回的generation交给done，状态切换前发出的请求结果不计入新状态
+func (b *breaker) allow() (generation uint64, err error) {
+	if b == nil {
+		return 0, nil
+	}
+
+	b.mutex.Lock()
+
+	var (
+		now    = time.Now()
+		from   = b.state
+		notify bool
+	)
+
+	switch b.state {
+	case BreakerOpen:
+		if wait := b.openDuration - now.Sub(b.openedAt); wait > 0 {
+			err = &CircuitOpenError{Node: b.node, RetryAfter: wait}
+			break
+		}
+
+		b.state, b.probes, b.successes, notify = BreakerHalfOpen, 1, 0, true
+		b.generation++
+	case BreakerHalfOpen:
+		if b.probes >= b.halfOpenRequests {
+			err = &CircuitOpenError{Node: b.node}
+			break
+		}
+		b.probes++
+	}
+
+	generation = b.generation
+	b.mutex.Unlock()
+
+	if notify {
+		b.notify(from, BreakerHalfOpen)
+	}
+
+	return generation, err
+}
+
+func (b *breaker) done(generation uint64, status int, duration time.Duration, err error) {
+	if b == nil {
+		return
+	}
+
+	failed := err != nil || status >= http.StatusInternalServerError || status == http.StatusTooManyRequests ||
+		(b.slow > 0 && duration > b.slow)
+
+	b.mutex.Lock()
+
+	if generation != b.generation {
+		b.mutex.Unlock()
+		return
+	}
+
+	var (
+		now  = time.Now()
+		from = b.state
+		to   = b.state
+	)
+
+	switch b.state {
+	case BreakerHalfOpen:
+		if failed {
+			to = BreakerOpen
+			break
+		}
+
+		b.successes++
+		if b.successes >= b.halfOpenRequests {
+			to = BreakerClosed
+		}
+	case BreakerClosed:
+		if now.Sub(b.windowStart) > b.window {
+			b.windowStart, b.total, b.failures = now, 0, 0
+		}
+
+		b.total++
+		if failed {
+			b.failures++
+		}
+
+		if b.total >= b.minRequests && float64(b.failures)/float64(b.total) >= b.errorRate {
+			to = BreakerOpen
+		}
+	}
+
+	switch {
+	case from == to:
+	case to == BreakerOpen:
+		b.state, b.openedAt = BreakerOpen, now
+		b.generation++
+	case to == BreakerClosed:
+		b.state, b.windowStart, b.total, b.failures = BreakerClosed, now, 0, 0
+		b.generation++
+	}
+
+	b.mutex.Unlock()
+
+	if from != to {
+		b.notify(from, to)
+	}
+}
+
+func (b *breaker) current() BreakerState {
+	if b == nil {
+		return BreakerClosed
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.state
+}
+
+func (b *breaker) notify(from, to BreakerState) {
+	if b.handler != nil {
+		b.handler(b.node, from, to)
+	}
+}
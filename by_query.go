@@ -0,0 +1,64 @@
+package elastic
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grpc-boot/elastic/results"
+
+	"github.com/grpc-boot/base"
+)
+
+// UpdateByQuery run script on documents matching where condition, Limit is sent as max_docs
+// link: https://www.elastic.co/guide/en/elasticsearch/reference/current/docs-update-by-query.html
+func (q *Query) UpdateByQuery(timeout time.Duration, conn *Connection, script *Script, opts ...*DocOptions) (*results.BulkByScrollResult, error) {
+	var scriptBytes []byte
+	if script != nil {
+		var err error
+		if scriptBytes, err = base.JsonMarshal(script); err != nil {
+			return nil, err
+		}
+	}
+
+	return q.byQuery(timeout, conn, "/_update_by_query", scriptBytes, opts)
+}
+
+// DeleteByQuery delete documents matching where condition, Limit is sent as max_docs
+// link: https://www.elastic.co/guide/en/elasticsearch/reference/current/docs-delete-by-query.html
+func (q *Query) DeleteByQuery(timeout time.Duration, conn *Connection, opts ...*DocOptions) (*results.BulkByScrollResult, error) {
+	return q.byQuery(timeout, conn, "/_delete_by_query", nil, opts)
+}
+
+func (q *Query) byQuery(timeout time.Duration, conn *Connection, action string, script []byte, opts []*DocOptions) (*results.BulkByScrollResult, error) {
+	query := q.buildQuery()
+
+	var body strings.Builder
+	body.Grow(len(query) + len(script) + 32)
+	body.WriteString(query[:len(query)-1])
+
+	if q.size > 0 {
+		body.WriteString(`,"max_docs":`)
+		body.WriteString(strconv.Itoa(q.size))
+	}
+
+	if len(script) > 0 {
+		body.WriteString(`,"script":`)
+		body.Write(script)
+	}
+
+	body.WriteByte('}')
+
+	result := &results.BulkByScrollResult{}
+	resp, err := conn.decode(timeout, http.MethodPost, withPath("/"+q.index+action, opts), body.String(), result)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.IsOk() {
+		return result, nil
+	}
+
+	return nil, resp.Error()
+}
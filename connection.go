@@ -1,10 +1,8 @@
 package elastic
 
 import (
-	"bytes"
 	"context"
 	"errors"
-	"io/ioutil"
 	"net"
 	"net/http"
 	"strconv"
@@ -15,6 +13,7 @@ import (
 
 	"github.com/grpc-boot/base"
 	"github.com/grpc-boot/base/core/zaplogger"
+	jsoniter "github.com/json-iterator/go"
 )
 
 type Connection struct {
@@ -22,6 +21,10 @@ type Connection struct {
 	baseUrl  string
 	username string
 	password string
+	slow     *slowLog
+	breaker  *breaker
+	limiters map[string]*limiter
+	maxBytes int64
 }
 
 func New(opt Option) *Connection {
@@ -43,21 +46,73 @@ func New(opt Option) *Connection {
 		baseUrl:  strings.TrimSuffix(option.BaseUrl, "/"),
 		username: option.UserName,
 		password: option.Password,
+		slow:     newSlowLog(option),
+		breaker:  newBreaker(option),
+		limiters: newLimiters(option),
+		maxBytes: option.MaxResponseBytes,
 	}
 }
 
+// BreakerState current state of circuit breaker, always BreakerClosed when breaker disabled
+func (c *Connection) BreakerState() BreakerState {
+	return c.breaker.current()
+}
+
 func (c *Connection) needAuth() bool {
 	return c.username != ""
 }
 
 func (c *Connection) request(timeout time.Duration, method, path string, params string) (response *Response, err error) {
+	response = &Response{}
+
+	err = c.send(timeout, method, path, params, func(resp *http.Response) (took func() int64, err error) {
+		response.Status = resp.StatusCode
+		response.Body, err = c.readBody(resp.Body)
+
+		return func() int64 { return tookOf(response.Body) }, err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return
+}
+
+// decode 成功响应直接从body流式解码到out，失败响应按字节读取以便返回错误信息
+func (c *Connection) decode(timeout time.Duration, method, path string, params string, out interface{}) (response *Response, err error) {
+	response = &Response{}
+
+	err = c.send(timeout, method, path, params, func(resp *http.Response) (took func() int64, err error) {
+		response.Status = resp.StatusCode
+
+		if !response.IsOk() {
+			response.Body, err = c.readBody(resp.Body)
+			return func() int64 { return tookOf(response.Body) }, err
+		}
+
+		body := c.limitBody(resp.Body)
+		if err = jsoniter.NewDecoder(body).Decode(out); err != nil && exceeded(body) {
+			err = ErrResponseTooLarge
+		}
+
+		return func() int64 { return tookOfResult(out) }, err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return
+}
+
+func (c *Connection) send(timeout time.Duration, method, path string, params string, read func(resp *http.Response) (took func() int64, err error)) (err error) {
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
-	buffer := bytes.NewBufferString(params)
-	req, err := http.NewRequestWithContext(ctx, method, c.baseUrl+path, buffer)
+	req, err := http.NewRequestWithContext(ctx, method, c.baseUrl+path, strings.NewReader(params))
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	if c.needAuth() {
@@ -66,36 +121,52 @@ func (c *Connection) request(timeout time.Duration, method, path string, params
 
 	req.Header.Add("Content-Type", "application/json")
 
+	release, err := c.limiters[classify(path)].acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	generation, err := c.breaker.allow()
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
 	resp, err := c.client.Do(req)
 	if err != nil {
+		duration := time.Since(start)
+		c.breaker.done(generation, 0, duration, err)
+		// 超时和传输失败通常就是最慢的请求
+		c.slow.check(method, path, params, 0, noTook, duration)
 		base.Error("es request failed",
 			zaplogger.Method(method),
 			zaplogger.Path(path),
 			zaplogger.Params(params),
 			zaplogger.Error(err),
 		)
-		return nil, err
+		return err
 	}
 
 	defer resp.Body.Close()
 
-	body, err := ioutil.ReadAll(resp.Body)
+	took, err := read(resp)
+	duration := time.Since(start)
+	c.breaker.done(generation, resp.StatusCode, duration, err)
 	if err != nil {
+		c.slow.check(method, path, params, resp.StatusCode, noTook, duration)
 		base.Error("request elastic failed",
 			zaplogger.Method(method),
 			zaplogger.Path(path),
 			zaplogger.Params(params),
 			zaplogger.Error(err),
 		)
-		return nil, err
+		return err
 	}
 
-	response = &Response{
-		Status: resp.StatusCode,
-		Body:   body,
-	}
+	c.slow.check(method, path, params, resp.StatusCode, took, duration)
 
-	return
+	return nil
 }
 
 func (c *Connection) Put(timeout time.Duration, path string, params string) (*Response, error) {
@@ -114,7 +185,13 @@ func (c *Connection) Delete(timeout time.Duration, path string, params string) (
 	return c.request(timeout, http.MethodDelete, path, params)
 }
 
-func (c *Connection) IndexCreate(timeout time.Duration, index string, settings *Settings, mappings *Mappings) (ok bool, err error) {
+func (c *Connection) Head(timeout time.Duration, path string) (*Response, error) {
+	return c.request(timeout, http.MethodHead, path, "")
+}
+
+// IndexCreate
+// link: https://www.elastic.co/guide/en/elasticsearch/reference/current/indices-create-index.html
+func (c *Connection) IndexCreate(timeout time.Duration, index string, settings *Settings, mappings *Mappings, aliases ...*Alias) (ok bool, err error) {
 	var body strings.Builder
 
 	body.WriteString(`{"settings":`)
@@ -125,6 +202,12 @@ func (c *Connection) IndexCreate(timeout time.Duration, index string, settings *
 		body.Write(mappings.Marshal())
 	}
 
+	if len(aliases) > 0 {
+		aliasesBytes, _ := base.JsonMarshal(aliasesMap(aliases))
+		body.WriteString(`,"aliases":`)
+		body.Write(aliasesBytes)
+	}
+
 	body.WriteByte('}')
 
 	resp, err := c.request(timeout, http.MethodPut, "/"+index, body.String())
@@ -186,6 +269,63 @@ func (c *Connection) MappingsAlter(timeout time.Duration, index string, mappings
 	return true, nil
 }
 
+// IndexMappings get mappings of index, index can be wildcard pattern or comma-separated list
+// link: https://www.elastic.co/guide/en/elasticsearch/reference/current/indices-get-mapping.html
+func (c *Connection) IndexMappings(timeout time.Duration, index string) (map[string]*Mappings, error) {
+	var result map[string]struct {
+		Mappings *Mappings `json:"mappings"`
+	}
+
+	resp, err := c.decode(timeout, http.MethodGet, "/"+index+"/_mapping", "", &result)
+	if err != nil {
+		return nil, err
+	}
+
+	if !resp.IsOk() {
+		return nil, resp.Error()
+	}
+
+	mappings := make(map[string]*Mappings, len(result))
+	for name, item := range result {
+		if item.Mappings == nil {
+			item.Mappings = &Mappings{}
+		}
+
+		item.Mappings.fillNames()
+		mappings[name] = item.Mappings
+	}
+
+	return mappings, nil
+}
+
+// IndexSettings get settings of index, index can be wildcard pattern or comma-separated list
+// link: https://www.elastic.co/guide/en/elasticsearch/reference/current/indices-get-settings.html
+func (c *Connection) IndexSettings(timeout time.Duration, index string) (map[string]*Settings, error) {
+	var result map[string]struct {
+		Settings struct {
+			Index base.JsonParam `json:"index"`
+		} `json:"settings"`
+	}
+
+	resp, err := c.decode(timeout, http.MethodGet, "/"+index+"/_settings", "", &result)
+	if err != nil {
+		return nil, err
+	}
+
+	if !resp.IsOk() {
+		return nil, resp.Error()
+	}
+
+	settings := make(map[string]*Settings, len(result))
+	for name, item := range result {
+		if settings[name], err = settingsFromIndex(item.Settings.Index); err != nil {
+			return nil, err
+		}
+	}
+
+	return settings, nil
+}
+
 // Bulk
 // link: https://www.elastic.co/guide/en/elasticsearch/reference/current/docs-bulk.html
 func (c *Connection) Bulk(timeout time.Duration, param string) (resp *Response, err error) {
@@ -193,6 +333,10 @@ func (c *Connection) Bulk(timeout time.Duration, param string) (resp *Response,
 }
 
 func (c *Connection) DocsBulk(timeout time.Duration, items ...BulkDoc) (resp *Response, err error) {
+	return c.DocsBulkWithOptions(timeout, items)
+}
+
+func (c *Connection) DocsBulkWithOptions(timeout time.Duration, items []BulkDoc, opts ...*DocOptions) (resp *Response, err error) {
 	if len(items) < 1 {
 		return nil, errors.New("items is required")
 	}
@@ -204,30 +348,39 @@ func (c *Connection) DocsBulk(timeout time.Duration, items ...BulkDoc) (resp *Re
 		buf.WriteByte('\n')
 	}
 
-	return c.Bulk(timeout, buf.String())
+	return c.Post(timeout, withPath("/_bulk", opts), buf.String())
 }
 
-// DocsInsert
+// DocsInsert _id of row creates document only when not exists, with IfSeqNo option it replaces document conditionally
 // link: https://www.elastic.co/guide/en/elasticsearch/reference/current/docs-index_.html
-func (c *Connection) DocsInsert(timeout time.Duration, index string, row base.JsonParam) (*results.IndexResult, error) {
+func (c *Connection) DocsInsert(timeout time.Duration, index string, row base.JsonParam, opts ...*DocOptions) (*results.IndexResult, error) {
 	var (
 		id, _ = row["_id"].(string)
 		path  strings.Builder
 	)
 
+	method := http.MethodPost
+
 	path.WriteByte('/')
 	path.WriteString(index)
 	path.WriteByte('/')
 
-	if id != "" {
+	switch {
+	case id != "" && conditional(opts):
+		// _create不支持if_seq_no，条件写入按id覆盖
+		method = http.MethodPut
+		path.WriteString(`_doc/`)
+		path.WriteString(id)
+		delete(row, "_id")
+	case id != "":
 		path.WriteString(`_create/`)
 		path.WriteString(id)
 		delete(row, "_id")
-	} else {
+	default:
 		path.WriteString(`_doc/`)
 	}
 
-	resp, err := c.Post(timeout, path.String(), base.Bytes2String(row.JsonMarshal()))
+	resp, err := c.request(timeout, method, withPath(path.String(), opts), base.Bytes2String(row.JsonMarshal()))
 	if err != nil {
 		return nil, err
 	}
@@ -241,7 +394,7 @@ func (c *Connection) DocsInsert(timeout time.Duration, index string, row base.Js
 
 // DocsUpdate
 // link: https://www.elastic.co/guide/en/elasticsearch/reference/current/docs-update.html
-func (c *Connection) DocsUpdate(timeout time.Duration, index string, id string, doc base.JsonParam) (*results.IndexResult, error) {
+func (c *Connection) DocsUpdate(timeout time.Duration, index string, id string, doc base.JsonParam, opts ...*DocOptions) (*results.IndexResult, error) {
 	var (
 		docBytes = doc.JsonMarshal()
 		n        = 7 + len(docBytes) + 1
@@ -254,7 +407,7 @@ func (c *Connection) DocsUpdate(timeout time.Duration, index string, id string,
 	body.Write(docBytes)
 	body.WriteByte('}')
 
-	resp, err := c.Post(timeout, "/"+index+"/_update/"+id, body.String())
+	resp, err := c.Post(timeout, withPath("/"+index+"/_update/"+id, opts), body.String())
 	if err != nil {
 		return nil, err
 	}
@@ -268,7 +421,7 @@ func (c *Connection) DocsUpdate(timeout time.Duration, index string, id string,
 
 // DocsUpdateWithVersion
 // link: https://www.elastic.co/guide/en/elasticsearch/reference/current/docs-index_.html
-func (c *Connection) DocsUpdateWithVersion(timeout time.Duration, index string, id string, version int64, fullDoc base.JsonParam) (*results.IndexResult, error) {
+func (c *Connection) DocsUpdateWithVersion(timeout time.Duration, index string, id string, version int64, fullDoc base.JsonParam, opts ...*DocOptions) (*results.IndexResult, error) {
 	var (
 		verStr = strconv.FormatInt(version, 10)
 		n      = 1 + len(index) + 6 + len(id) + 9 + len(verStr) + 25
@@ -285,7 +438,7 @@ func (c *Connection) DocsUpdateWithVersion(timeout time.Duration, index string,
 	path.WriteString(verStr)
 	path.WriteString("&version_type=external_gt")
 
-	resp, err := c.Put(timeout, path.String(), base.Bytes2String(fullDoc.JsonMarshal()))
+	resp, err := c.Put(timeout, withPath(path.String(), opts), base.Bytes2String(fullDoc.JsonMarshal()))
 	if err != nil {
 		return nil, err
 	}
@@ -299,8 +452,8 @@ func (c *Connection) DocsUpdateWithVersion(timeout time.Duration, index string,
 
 // DocsGet
 // link: https://www.elastic.co/guide/en/elasticsearch/reference/current/docs-get.html
-func (c *Connection) DocsGet(timeout time.Duration, index string, id string) (*results.DocumentResult, error) {
-	resp, err := c.Get(timeout, "/"+index+"/_doc/"+id, "")
+func (c *Connection) DocsGet(timeout time.Duration, index string, id string, opts ...*DocOptions) (*results.DocumentResult, error) {
+	resp, err := c.Get(timeout, withPath("/"+index+"/_doc/"+id, opts), "")
 	if err != nil {
 		return nil, err
 	}
@@ -312,25 +465,72 @@ func (c *Connection) DocsGet(timeout time.Duration, index string, id string) (*r
 	return nil, resp.Error()
 }
 
+// DocsExists check existence without loading source
+// link: https://www.elastic.co/guide/en/elasticsearch/reference/current/docs-get.html
+func (c *Connection) DocsExists(timeout time.Duration, index string, id string, opts ...*DocOptions) (exists bool, err error) {
+	resp, err := c.Head(timeout, withPath("/"+index+"/_doc/"+id, opts))
+	if err != nil {
+		return
+	}
+
+	if resp.IsOk() {
+		return true, nil
+	}
+
+	if resp.Is(http.StatusNotFound) {
+		return false, nil
+	}
+
+	return false, resp.Error()
+}
+
+// DocsSource only source of document without metadata, ErrDocumentNotFound when document not exists
+// link: https://www.elastic.co/guide/en/elasticsearch/reference/current/docs-get.html
+func (c *Connection) DocsSource(timeout time.Duration, index string, id string, opts ...*DocOptions) (source base.JsonParam, err error) {
+	resp, err := c.decode(timeout, http.MethodGet, withPath("/"+index+"/_source/"+id, opts), "", &source)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.IsOk() {
+		return source, nil
+	}
+
+	if resp.Is(http.StatusNotFound) {
+		return nil, ErrDocumentNotFound
+	}
+
+	return nil, resp.Error()
+}
+
 // DocsMGet
 // link: https://www.elastic.co/guide/en/elasticsearch/reference/current/docs-multi-get.html
 func (c *Connection) DocsMGet(timeout time.Duration, index string, idList ...string) (rows *results.DocumentsResult, err error) {
+	return c.DocsMGetWithOptions(timeout, index, idList)
+}
+
+func (c *Connection) DocsMGetWithOptions(timeout time.Duration, index string, idList []string, opts ...*DocOptions) (rows *results.DocumentsResult, err error) {
 	param, _ := base.JsonMarshal(map[string]interface{}{
 		"ids": idList,
 	})
 
-	resp, err := c.Get(timeout, "/"+index+"/_mget", base.Bytes2String(param))
+	rows = &results.DocumentsResult{}
+	resp, err := c.decode(timeout, http.MethodGet, withPath("/"+index+"/_mget", opts), base.Bytes2String(param), rows)
 	if err != nil {
 		return nil, err
 	}
 
 	if resp.IsOk() {
-		return resp.UnmarshalDocumentsResult()
+		return rows, nil
 	}
 	return nil, resp.Error()
 }
 
 func (c *Connection) DocsMSet(timeout time.Duration, index string, rows ...base.JsonParam) (resp *Response, err error) {
+	return c.DocsMSetWithOptions(timeout, index, rows)
+}
+
+func (c *Connection) DocsMSetWithOptions(timeout time.Duration, index string, rows []base.JsonParam, opts ...*DocOptions) (resp *Response, err error) {
 	var (
 		items = make([]BulkDoc, len(rows), len(rows))
 		id    = ""
@@ -344,13 +544,13 @@ func (c *Connection) DocsMSet(timeout time.Duration, index string, rows ...base.
 		items[i] = IndexDoc(index, id, row)
 	}
 
-	return c.DocsBulk(timeout, items...)
+	return c.DocsBulkWithOptions(timeout, items, opts...)
 }
 
 // DocsDelete
 // link: https://www.elastic.co/guide/en/elasticsearch/reference/current/docs-delete.html
-func (c *Connection) DocsDelete(timeout time.Duration, index, id string) (*results.IndexResult, error) {
-	resp, err := c.Delete(timeout, "/"+index+"/_doc/"+id, "")
+func (c *Connection) DocsDelete(timeout time.Duration, index, id string, opts ...*DocOptions) (*results.IndexResult, error) {
+	resp, err := c.Delete(timeout, withPath("/"+index+"/_doc/"+id, opts), "")
 	if err != nil {
 		return nil, err
 	}
@@ -372,13 +572,14 @@ func (c *Connection) SqlSearch(timeout time.Duration, sql string) (*results.SqlS
 	param.WriteString(sql)
 	param.WriteString(`"}`)
 
-	resp, err := c.Post(timeout, "/_sql", param.String())
+	result := &results.SqlSearchResult{}
+	resp, err := c.decode(timeout, http.MethodPost, "/_sql", param.String(), result)
 	if err != nil {
 		return nil, err
 	}
 
 	if resp.IsOk() {
-		return resp.UnmarshalSqlSearchResult()
+		return result, nil
 	}
 
 	return nil, resp.Error()
@@ -0,0 +1,189 @@
+package elastic
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	RefreshTrue    = `true`
+	RefreshFalse   = `false`
+	RefreshWaitFor = `wait_for`
+
+	ActiveShardsAll = `all`
+
+	ConflictsAbort   = `abort`
+	ConflictsProceed = `proceed`
+)
+
+// DocOptions url parameters of document apis
+type DocOptions struct {
+	values url.Values
+}
+
+func NewDocOptions() *DocOptions {
+	return &DocOptions{values: url.Values{}}
+}
+
+// IfSeqNo write only when document has not been changed since it was read with seqNo and primaryTerm,
+// otherwise server responds 409 and errors.Is(err, ErrVersionConflict) reports true
+// link: https://www.elastic.co/guide/en/elasticsearch/reference/current/optimistic-concurrency-control.html
+func (do *DocOptions) IfSeqNo(seqNo int64, primaryTerm int64) *DocOptions {
+	do.values.Set("if_seq_no", strconv.FormatInt(seqNo, 10))
+	do.values.Set("if_primary_term", strconv.FormatInt(primaryTerm, 10))
+	return do
+}
+
+// Refresh make the change visible to search, RefreshWaitFor waits for the next periodic refresh
+// link: https://www.elastic.co/guide/en/elasticsearch/reference/current/docs-refresh.html
+func (do *DocOptions) Refresh(refresh string) *DocOptions {
+	do.values.Set("refresh", refresh)
+	return do
+}
+
+// Routing must be same for write and read of a document on routed index
+func (do *DocOptions) Routing(routing string) *DocOptions {
+	do.values.Set("routing", routing)
+	return do
+}
+
+// Timeout wait time of server for unavailable shards, it is not the http timeout
+func (do *DocOptions) Timeout(timeout time.Duration) *DocOptions {
+	do.values.Set("timeout", strconv.FormatInt(timeout.Milliseconds(), 10)+"ms")
+	return do
+}
+
+// WaitForActiveShards number of shard copies or ActiveShardsAll
+func (do *DocOptions) WaitForActiveShards(shards string) *DocOptions {
+	do.values.Set("wait_for_active_shards", shards)
+	return do
+}
+
+// Pipeline ingest pipeline preprocessing the documents
+func (do *DocOptions) Pipeline(pipeline string) *DocOptions {
+	do.values.Set("pipeline", pipeline)
+	return do
+}
+
+// RetryOnConflict times to retry update when document is changed between get and index phases of update
+func (do *DocOptions) RetryOnConflict(times int) *DocOptions {
+	do.values.Set("retry_on_conflict", strconv.Itoa(times))
+	return do
+}
+
+// Source return source of document in update response
+func (do *DocOptions) Source(enabled bool) *DocOptions {
+	do.values.Set("_source", strconv.FormatBool(enabled))
+	return do
+}
+
+// SourceIncludes only return these source fields of get apis, wildcard is supported
+func (do *DocOptions) SourceIncludes(fields ...string) *DocOptions {
+	do.values.Set("_source_includes", strings.Join(fields, ","))
+	return do
+}
+
+// SourceExcludes return source fields except these of get apis, wildcard is supported
+func (do *DocOptions) SourceExcludes(fields ...string) *DocOptions {
+	do.values.Set("_source_excludes", strings.Join(fields, ","))
+	return do
+}
+
+// StoredFields return stored fields instead of source of get apis, fields must be mapped with store
+func (do *DocOptions) StoredFields(fields ...string) *DocOptions {
+	do.values.Set("stored_fields", strings.Join(fields, ","))
+	return do
+}
+
+// Conflicts ConflictsProceed counts version conflicts instead of aborting by query apis
+func (do *DocOptions) Conflicts(conflicts string) *DocOptions {
+	do.values.Set("conflicts", conflicts)
+	return do
+}
+
+// Slices parallelize by query apis, slices less than 1 lets server pick one slice per shard
+func (do *DocOptions) Slices(slices int) *DocOptions {
+	if slices < 1 {
+		do.values.Set("slices", "auto")
+		return do
+	}
+
+	do.values.Set("slices", strconv.Itoa(slices))
+	return do
+}
+
+// RequestsPerSecond throttle by query apis, requests not greater than 0 means no throttle
+func (do *DocOptions) RequestsPerSecond(requests float64) *DocOptions {
+	if requests <= 0 {
+		do.values.Set("requests_per_second", "-1")
+		return do
+	}
+
+	do.values.Set("requests_per_second", strconv.FormatFloat(requests, 'f', -1, 64))
+	return do
+}
+
+// Async return task id instead of waiting by query apis to complete
+func (do *DocOptions) Async() *DocOptions {
+	do.values.Set("wait_for_completion", "false")
+	return do
+}
+
+// pick 只保留指定参数
+func pick(opts []*DocOptions, keys ...string) *DocOptions {
+	picked := NewDocOptions()
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+
+		for _, key := range keys {
+			if value, ok := opt.values[key]; ok {
+				picked.values[key] = value
+			}
+		}
+	}
+
+	return picked
+}
+
+// conditional if_seq_no is set by any option
+func conditional(opts []*DocOptions) bool {
+	for _, opt := range opts {
+		if opt != nil && opt.values.Has("if_seq_no") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// withPath 多个选项合并，相同参数后者覆盖前者
+func withPath(path string, opts []*DocOptions) string {
+	var values url.Values
+
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+
+		for key, value := range opt.values {
+			if values == nil {
+				values = url.Values{}
+			}
+			values[key] = value
+		}
+	}
+
+	if len(values) == 0 {
+		return path
+	}
+
+	if strings.IndexByte(path, '?') > -1 {
+		return path + "&" + values.Encode()
+	}
+
+	return path + "?" + values.Encode()
+}
@@ -0,0 +1,113 @@
+package elastic
+
+import (
+	"errors"
+
+	"github.com/grpc-boot/base"
+)
+
+const (
+	MappingTypeObject  = `object`
+	MappingTypeString  = `string`
+	MappingTypeLong    = `long`
+	MappingTypeDouble  = `double`
+	MappingTypeBoolean = `boolean`
+	MappingTypeDate    = `date`
+	MappingTypeBinary  = `binary`
+	MappingTypeAll     = `*`
+
+	// DynamicType placeholder of detected type in mapping of dynamic template
+	DynamicType = `{dynamic_type}`
+)
+
+// DynamicTemplate
+// link: https://www.elastic.co/guide/en/elasticsearch/reference/current/dynamic-templates.html
+type DynamicTemplate struct {
+	n                string
+	MatchMappingType string    `json:"match_mapping_type,omitempty"`
+	MatchPattern     string    `json:"match_pattern,omitempty"`
+	Match            string    `json:"match,omitempty"`
+	Unmatch          string    `json:"unmatch,omitempty"`
+	PathMatch        string    `json:"path_match,omitempty"`
+	PathUnmatch      string    `json:"path_unmatch,omitempty"`
+	Mapping          *Property `json:"mapping,omitempty"`
+}
+
+func NewDynamicTemplate(name string) *DynamicTemplate {
+	return &DynamicTemplate{n: name}
+}
+
+func (dt *DynamicTemplate) Name() string {
+	return dt.n
+}
+
+// WithMatchMappingType one of MappingType* constants
+func (dt *DynamicTemplate) WithMatchMappingType(mappingType string) *DynamicTemplate {
+	dt.MatchMappingType = mappingType
+	return dt
+}
+
+// WithMatchRegex match and unmatch are regular expressions instead of wildcard patterns
+func (dt *DynamicTemplate) WithMatchRegex() *DynamicTemplate {
+	dt.MatchPattern = `regex`
+	return dt
+}
+
+func (dt *DynamicTemplate) WithMatch(pattern string) *DynamicTemplate {
+	dt.Match = pattern
+	return dt
+}
+
+func (dt *DynamicTemplate) WithUnmatch(pattern string) *DynamicTemplate {
+	dt.Unmatch = pattern
+	return dt
+}
+
+func (dt *DynamicTemplate) WithPathMatch(pattern string) *DynamicTemplate {
+	dt.PathMatch = pattern
+	return dt
+}
+
+func (dt *DynamicTemplate) WithPathUnmatch(pattern string) *DynamicTemplate {
+	dt.PathUnmatch = pattern
+	return dt
+}
+
+func (dt *DynamicTemplate) WithMapping(mapping *Property) *DynamicTemplate {
+	dt.Mapping = mapping
+	return dt
+}
+
+// DynamicTemplates keep order of templates, marshal as [{"name":{...}}]
+type DynamicTemplates []*DynamicTemplate
+
+func (dts DynamicTemplates) MarshalJSON() ([]byte, error) {
+	items := make([]map[string]*DynamicTemplate, len(dts))
+	for index, dt := range dts {
+		items[index] = map[string]*DynamicTemplate{dt.n: dt}
+	}
+
+	return base.JsonMarshal(items)
+}
+
+func (dts *DynamicTemplates) UnmarshalJSON(data []byte) error {
+	var items []map[string]*DynamicTemplate
+	if err := base.JsonUnmarshal(data, &items); err != nil {
+		return err
+	}
+
+	list := make(DynamicTemplates, 0, len(items))
+	for _, item := range items {
+		if len(item) != 1 {
+			return errors.New("invalid dynamic template")
+		}
+
+		for name, dt := range item {
+			dt.n = name
+			list = append(list, dt)
+		}
+	}
+
+	*dts = list
+	return nil
+}
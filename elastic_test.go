@@ -1,10 +1,20 @@
 package elastic
 
 import (
+	"context"
+	"errors"
+	"io"
 	"math/rand"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/grpc-boot/elastic/results"
+
 	"github.com/grpc-boot/base"
 	"github.com/grpc-boot/base/core/zaplogger"
 	"go.uber.org/zap/zapcore"
@@ -338,3 +348,1401 @@ func TestQuery_Search(t *testing.T) {
 
 	t.Logf("result: %+v error:%v", result, err)
 }
+
+func TestSlowLog_Check(t *testing.T) {
+	var reported []SlowRequest
+
+	sl := newSlowLog(loadOption(Option{
+		SlowThresholdMillisecond: 100,
+		SlowBodyMaxLength:        8,
+		SlowHandler: func(sr SlowRequest) {
+			reported = append(reported, sr)
+		},
+	}))
+
+	sl.check("GET", "/user/_search", `{"query":{}}`, 200, func() int64 { return tookOf([]byte(`{"took":87,"timed_out":false}`)) }, time.Millisecond*50)
+	if len(reported) != 0 {
+		t.Fatalf("want 0, got %d", len(reported))
+	}
+
+	sl.check("GET", "/user/_search", `{"query":{}}`, 200, func() int64 { return tookOf([]byte(`{"took":87,"timed_out":false}`)) }, time.Millisecond*150)
+	if len(reported) != 1 {
+		t.Fatalf("want 1, got %d", len(reported))
+	}
+
+	if reported[0].Took != 87 {
+		t.Fatalf("want 87, got %d", reported[0].Took)
+	}
+
+	if reported[0].Body != `{"query"...` {
+		t.Fatalf("want truncated body, got %s", reported[0].Body)
+	}
+
+	t.Logf("slow request: %+v", reported[0])
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(time.Millisecond * 200)
+	}))
+	defer server.Close()
+
+	c := New(Option{
+		BaseUrl:                  server.URL,
+		SlowThresholdMillisecond: 50,
+		SlowHandler: func(sr SlowRequest) {
+			reported = append(reported, sr)
+		},
+	})
+
+	if _, err := c.Get(time.Millisecond*100, "/user/_search", ""); err == nil {
+		t.Fatal("want timeout error")
+	}
+
+	if len(reported) != 2 || reported[1].Status != 0 || reported[1].Took != -1 {
+		t.Fatalf("want timed out request reported, got %+v", reported)
+	}
+}
+
+func TestTookOfResult(t *testing.T) {
+	typed := &results.TypedSearchResult[testUser]{Took: 12}
+	if took := tookOfResult(typed); took != 12 {
+		t.Fatalf("want 12, got %d", took)
+	}
+
+	if took := tookOfResult(&results.BulkByScrollResult{Took: 34}); took != 34 {
+		t.Fatalf("want 34, got %d", took)
+	}
+
+	if took := tookOfResult(&results.BulkByScrollResult{Task: "node:1"}); took != -1 {
+		t.Fatalf("want -1 for async task, got %d", took)
+	}
+
+	if took := tookOfResult(&results.SearchResult{Took: 56}); took != 56 {
+		t.Fatalf("want 56, got %d", took)
+	}
+
+	if took := tookOfResult(&results.CountResult{}); took != -1 {
+		t.Fatalf("want -1, got %d", took)
+	}
+}
+
+func TestBreaker_Allow(t *testing.T) {
+	var changes []BreakerState
+
+	b := newBreaker(loadOption(Option{
+		BaseUrl:            "http://127.0.0.1:9200",
+		BreakerErrorRate:   0.5,
+		BreakerMinRequests: 4,
+		BreakerOpenSecond:  1,
+		BreakerStateHandler: func(node string, from, to BreakerState) {
+			t.Logf("node:%s %s => %s", node, from, to)
+			changes = append(changes, to)
+		},
+	}))
+
+	// 熔断前放行的请求，在半开状态下才返回
+	straggler, _ := b.allow()
+
+	for i := 0; i < 4; i++ {
+		generation, err := b.allow()
+		if err != nil {
+			t.Fatalf("want nil, got %s", err)
+		}
+		b.done(generation, http.StatusServiceUnavailable, time.Millisecond, nil)
+	}
+
+	_, err := b.allow()
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("want ErrCircuitOpen, got %v", err)
+	}
+
+	b.openedAt = b.openedAt.Add(-time.Second)
+	probe, err := b.allow()
+	if err != nil {
+		t.Fatalf("want nil, got %s", err)
+	}
+
+	if _, err = b.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("want ErrCircuitOpen, got %v", err)
+	}
+
+	b.done(straggler, http.StatusOK, time.Millisecond, nil)
+	if b.current() != BreakerHalfOpen {
+		t.Fatalf("want half-open, got %s", b.current())
+	}
+
+	b.done(probe, http.StatusOK, time.Millisecond, nil)
+	if b.current() != BreakerClosed {
+		t.Fatalf("want closed, got %s", b.current())
+	}
+
+	if len(changes) != 3 {
+		t.Fatalf("want 3 state changes, got %d", len(changes))
+	}
+}
+
+func TestLimiter_Acquire(t *testing.T) {
+	l := newLimiter(LimitOption{Rate: 10, Burst: 1, MaxInFlight: 1, FailFast: true})
+
+	release, err := l.acquire(context.Background())
+	if err != nil {
+		t.Fatalf("want nil, got %s", err)
+	}
+
+	if _, err = l.acquire(context.Background()); err != ErrLimited {
+		t.Fatalf("want ErrLimited, got %v", err)
+	}
+	release()
+
+	time.Sleep(time.Millisecond * 110)
+	release, err = l.acquire(context.Background())
+	if err != nil {
+		t.Fatalf("want nil, got %s", err)
+	}
+	release()
+
+	// 并发数拒绝的请求不消耗令牌
+	l = newLimiter(LimitOption{Rate: 1, Burst: 2, MaxInFlight: 1, FailFast: true})
+	release, err = l.acquire(context.Background())
+	if err != nil {
+		t.Fatalf("want nil, got %s", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err = l.acquire(context.Background()); err != ErrLimited {
+			t.Fatalf("want ErrLimited, got %v", err)
+		}
+	}
+	release()
+
+	if release, err = l.acquire(context.Background()); err != nil {
+		t.Fatalf("want nil, got %s", err)
+	}
+	release()
+
+	t.Logf("class: %s %s %s", classify("/user/_search"), classify("/_bulk"), classify("/user/_settings"))
+}
+
+func TestConnection_Decode(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"took":3,"timed_out":false,"hits":{"total":{"value":1,"relation":"eq"},"hits":[{"_index":"user","_id":"1","_source":{"name":"name_1"}}]}}`))
+	}))
+	defer server.Close()
+
+	c := New(Option{BaseUrl: server.URL})
+	result, err := (&Query{}).From("user").Search(time.Second, c)
+	if err != nil {
+		t.Fatalf("want nil, got %s", err)
+	}
+
+	if result.Took != 3 || len(result.Hits.Hits) != 1 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	c = New(Option{BaseUrl: server.URL, MaxResponseBytes: 16})
+	if _, err = (&Query{}).From("user").Search(time.Second, c); !errors.Is(err, ErrResponseTooLarge) {
+		t.Fatalf("want ErrResponseTooLarge, got %v", err)
+	}
+
+	if _, err = c.Get(time.Second, "/user/_search", ""); !errors.Is(err, ErrResponseTooLarge) {
+		t.Fatalf("want ErrResponseTooLarge, got %v", err)
+	}
+}
+
+type testUser struct {
+	Name   string `json:"name"`
+	Status int8   `json:"status"`
+}
+
+func TestDecodeHits(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !strings.Contains(string(body), `"version":true,"seq_no_primary_term":true`) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`{"took":3,"hits":{"total":{"value":1,"relation":"eq"},"hits":[{"_index":"user","_id":"1","_version":2,"_seq_no":9,"_primary_term":1,"_score":1.5,"sort":[1],"_source":{"name":"name_1","status":1}}]}}`))
+	}))
+	defer server.Close()
+
+	c := New(Option{BaseUrl: server.URL})
+	sr, err := (&Query{}).From("user").WithSeqNo().Search(time.Second, c)
+	if err != nil {
+		t.Fatalf("want nil, got %s", err)
+	}
+
+	hits, err := results.DecodeHits[testUser](sr)
+	if err != nil {
+		t.Fatalf("want nil, got %s", err)
+	}
+
+	if len(hits) != 1 || hits[0].Id != "1" || hits[0].Version != 2 || hits[0].SeqNo != 9 || hits[0].PrimaryTerm != 1 || hits[0].Source.Name != "name_1" {
+		t.Fatalf("unexpected hits: %+v", hits)
+	}
+
+	typed, err := SearchAs[testUser](time.Second, c, (&Query{}).From("user").WithSeqNo())
+	if err != nil {
+		t.Fatalf("want nil, got %s", err)
+	}
+
+	t.Logf("sources: %+v", typed.Sources())
+}
+
+func TestDecodeHits_BigInt(t *testing.T) {
+	sr := &results.SearchResult{}
+	err := base.JsonUnmarshal([]byte(`{"hits":{"hits":[{"_index":"order","_id":"1","_source":{"id":1234567890123456789,"name":"order_1"}}]}}`), sr)
+	if err != nil {
+		t.Fatalf("want nil, got %s", err)
+	}
+
+	type order struct {
+		Id   uint64 `json:"id"`
+		Name string `json:"name"`
+	}
+
+	hits, err := results.DecodeHits[order](sr)
+	if err != nil {
+		t.Fatalf("want nil, got %s", err)
+	}
+
+	if len(hits) != 1 || hits[0].Source.Id != 1234567890123456789 || hits[0].Source.Name != "order_1" {
+		t.Fatalf("unexpected hits: %+v", hits)
+	}
+
+	if sr.Hits.Hits[0].Source.String("name") != "order_1" {
+		t.Fatalf("want source kept, got %+v", sr.Hits.Hits[0].Source)
+	}
+}
+
+func TestDocsGetAs_Options(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("routing") != "tenant1" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/user/_doc/1":
+			_, _ = w.Write([]byte(`{"_index":"user","_id":"1","found":true,"_source":{"name":"name_1"}}`))
+		case "/user/_mget":
+			_, _ = w.Write([]byte(`{"docs":[{"_index":"user","_id":"1","found":true,"_source":{"name":"name_1"}}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	c := New(Option{BaseUrl: server.URL})
+	opts := NewDocOptions().Routing(`tenant1`)
+
+	doc, err := DocsGetAs[testUser](time.Second, c, `user`, "1", opts)
+	if err != nil {
+		t.Fatalf("want nil, got %s", err)
+	}
+
+	if !doc.Found || doc.Source.Name != "name_1" {
+		t.Fatalf("unexpected doc: %+v", doc)
+	}
+
+	docs, err := DocsMGetAsWithOptions[testUser](time.Second, c, `user`, []string{"1"}, opts)
+	if err != nil {
+		t.Fatalf("want nil, got %s", err)
+	}
+
+	if rows := docs.ToRows(); len(rows) != 1 || rows[0].Name != "name_1" {
+		t.Fatalf("unexpected docs: %+v", docs)
+	}
+}
+
+func TestDocsGetAs(t *testing.T) {
+	res, err := DocsGetAs[testUser](time.Second*3, conn, `user`, "100")
+	if err != nil {
+		t.Fatalf("want nil, got %s", err)
+	}
+
+	t.Logf("res: %+v", res)
+
+	rows, err := DocsMGetAs[testUser](time.Second*3, conn, `user`, "100", "101", "1")
+	if err != nil {
+		t.Fatalf("want nil, got %s", err)
+	}
+
+	t.Logf("rows: %+v", rows.ToRows())
+}
+
+type testAddress struct {
+	City     string `json:"city"`
+	Location string `es:"location,type=geo_point"`
+}
+
+type testBase struct {
+	Id        uint64    `json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type testDocument struct {
+	testBase
+
+	Name          string        `json:"name" es:",type=text"`
+	LastLoginTime int64         `es:"lastLoginTime,type=date,format=epoch_second"`
+	LastLoginIp   net.IP        `json:"lastLoginIp"`
+	Tags          []int8        `json:"tags"`
+	Address       testAddress   `json:"address"`
+	History       []testAddress `es:"history,type=nested"`
+	Password      string        `json:"-"`
+	Extra         interface{}   `json:"extra"`
+}
+
+func TestMappingsFromStruct(t *testing.T) {
+	mappings, err := MappingsFromStruct(&testDocument{})
+	if err != nil {
+		t.Fatalf("want nil, got %s", err)
+	}
+
+	t.Logf("mappings: %s", mappings.Marshal())
+
+	want := map[string]string{
+		"id":            TypeUlong,
+		"createdAt":     TypeDate,
+		"name":          TypeText,
+		"lastLoginTime": TypeDate,
+		"lastLoginIp":   TypeIp,
+		"tags":          TypeByte,
+		"address":       TypeObject,
+		"history":       TypeNested,
+	}
+
+	if len(mappings.Properties) != len(want) {
+		t.Fatalf("want %d properties, got %d", len(want), len(mappings.Properties))
+	}
+
+	for name, fieldType := range want {
+		if prop, ok := mappings.Properties[name]; !ok || prop.Type != fieldType {
+			t.Fatalf("want %s:%s, got %+v", name, fieldType, prop)
+		}
+	}
+
+	if mappings.Properties["history"].Properties["location"].Type != "geo_point" {
+		t.Fatalf("want geo_point, got %+v", mappings.Properties["history"].Properties["location"])
+	}
+}
+
+type TestCode string
+
+func TestMappingsFromStruct_Embedded(t *testing.T) {
+	type event struct {
+		time.Time
+		TestCode
+		Name string `json:"name"`
+	}
+
+	mappings, err := MappingsFromStruct(&event{})
+	if err != nil {
+		t.Fatalf("want nil, got %s", err)
+	}
+
+	t.Logf("mappings: %s", mappings.Marshal())
+
+	if _, ok := mappings.Properties[""]; ok {
+		t.Fatalf("want no empty property name, got %s", mappings.Marshal())
+	}
+
+	want := map[string]string{
+		"Time":     TypeDate,
+		"TestCode": TypeKeyword,
+		"name":     TypeKeyword,
+	}
+
+	if len(mappings.Properties) != len(want) {
+		t.Fatalf("want %d properties, got %s", len(want), mappings.Marshal())
+	}
+
+	for name, fieldType := range want {
+		if prop, ok := mappings.Properties[name]; !ok || prop.Type != fieldType {
+			t.Fatalf("want %s:%s, got %+v", name, fieldType, prop)
+		}
+	}
+}
+
+func TestMappings_Marshal(t *testing.T) {
+	mappings := &Mappings{}
+	mappings.Add(
+		NewProperty(`title`, TypeText).
+			WithAnalyzer(`standard`).
+			WithSearchAnalyzer(`simple`).
+			WithCopyTo(`all`).
+			WithFields(NewProperty(`keyword`, TypeKeyword).WithIgnoreAbove(256)),
+		NewProperty(`price`, TypeScaledFloat).WithScalingFactor(100),
+		NewProperty(`raw`, TypeKeyword).WithIndex(false).WithDocValues(false).WithNullValue(`NULL`),
+		NewProperty(`vector`, TypeDenseVector).WithDims(3, `cosine`),
+		NewProperty(`relation`, TypeJoin).WithRelations(`question`, `answer`, `comment`),
+		NewProperty(`name`, TypeAlias).WithPath(`title`),
+		NewProperty(`address`, TypeObject).WithProperties(
+			NewProperty(`city`, TypeKeyword),
+			NewProperty(`location`, TypeGeoPoint),
+		),
+	)
+
+	data := string(mappings.Marshal())
+	t.Logf("mappings: %s", data)
+
+	for _, want := range []string{
+		`"fields":{"keyword":{"type":"keyword","ignore_above":256}}`,
+		`"index":false,"doc_values":false,"null_value":"NULL"`,
+		`"relations":{"question":["answer","comment"]}`,
+		`"properties":{`,
+	} {
+		if !strings.Contains(data, want) {
+			t.Fatalf("want %s in %s", want, data)
+		}
+	}
+}
+
+func TestMappings_Options(t *testing.T) {
+	mappings := &Mappings{}
+	mappings.WithDynamic(DynamicStrict).
+		WithDateDetection(false).
+		WithSourceExcludes(`content`).
+		WithRoutingRequired(true).
+		WithMeta(`version`, 1).
+		AddDynamicTemplates(
+			NewDynamicTemplate(`strings_as_keywords`).
+				WithMatchMappingType(MappingTypeString).
+				WithMapping(NewProperty(``, TypeKeyword).WithIgnoreAbove(256)),
+			NewDynamicTemplate(`longs`).
+				WithMatch(`*_count`).
+				WithMapping(NewProperty(``, TypeLong)),
+		).
+		Add(NewProperty(`id`, TypeUlong))
+
+	data := mappings.Marshal()
+	t.Logf("mappings: %s", data)
+
+	parsed := &Mappings{}
+	if err := base.JsonUnmarshal(data, parsed); err != nil {
+		t.Fatalf("want nil, got %s", err)
+	}
+
+	if len(parsed.DynamicTemplates) != 2 || parsed.DynamicTemplates[1].Name() != `longs` {
+		t.Fatalf("unexpected dynamic templates: %+v", parsed.DynamicTemplates)
+	}
+
+	if parsed.Dynamic != DynamicStrict || !parsed.Routing.Required {
+		t.Fatalf("unexpected mappings: %+v", parsed)
+	}
+}
+
+func TestSettings_Marshal(t *testing.T) {
+	set := &Settings{NumberOfShards: 1}
+	set.WithRefreshInterval(`30s`).
+		WithMaxResultWindow(50000).
+		WithCodec(CodecBestCompression).
+		WithSort(Desc(`lastLoginTime`)).
+		AddTokenizer(`name_ngram`, `ngram`, base.JsonParam{"min_gram": 2, "max_gram": 3}).
+		AddFilter(`name_stop`, `stop`, base.JsonParam{"stopwords": []string{"the"}}).
+		AddAnalyzer(`name_analyzer`, CustomAnalyzer(`name_ngram`, `lowercase`, `name_stop`)).
+		AddNormalizer(`lower`, CustomNormalizer(`lowercase`, `asciifolding`))
+
+	data := string(set.Marshal())
+	t.Logf("settings: %s", data)
+
+	for _, want := range []string{
+		`"sort":{"field":["lastLoginTime"],"order":["desc"]}`,
+		`"codec":"best_compression"`,
+		`"analyzer":{"name_analyzer":{"type":"custom","tokenizer":"name_ngram","filter":["lowercase","name_stop"]}}`,
+	} {
+		if !strings.Contains(data, want) {
+			t.Fatalf("want %s in %s", want, data)
+		}
+	}
+}
+
+func TestConnection_IndexMappingsSettings(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/user*/_mapping":
+			_, _ = w.Write([]byte(`{"user_v1":{"mappings":{"dynamic":"strict","properties":{"address":{"properties":{"city":{"type":"keyword"}}},"content":{"type":"text","fields":{"keyword":{"type":"keyword","ignore_above":256}}}}}}}`))
+		case "/user*/_settings":
+			_, _ = w.Write([]byte(`{"user_v1":{"settings":{"index":{"number_of_shards":"2","number_of_replicas":"1","refresh_interval":"30s","sort":{"field":"lastLoginTime","order":"desc"},"analysis":{"tokenizer":{"name_ngram":{"type":"ngram","min_gram":"2"}}},"uuid":"abc","creation_date":"1666666666"}}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	c := New(Option{BaseUrl: server.URL})
+
+	mappings, err := c.IndexMappings(time.Second, `user*`)
+	if err != nil {
+		t.Fatalf("want nil, got %s", err)
+	}
+
+	content := mappings["user_v1"].Properties["content"]
+	if mappings["user_v1"].Dynamic != DynamicStrict || content.FieldName() != "content" || content.Fields["keyword"].FieldName() != "keyword" {
+		t.Fatalf("unexpected mappings: %s", mappings["user_v1"].Marshal())
+	}
+
+	settings, err := c.IndexSettings(time.Second, `user*`)
+	if err != nil {
+		t.Fatalf("want nil, got %s", err)
+	}
+
+	set := settings["user_v1"]
+	if set.NumberOfShards != 2 || set.RefreshInterval != "30s" || set.Sort.Field[0] != "lastLoginTime" {
+		t.Fatalf("unexpected settings: %s", set.Marshal())
+	}
+
+	t.Logf("settings: %s", set.Marshal())
+}
+
+func TestDiffSchema(t *testing.T) {
+	live := &Mappings{}
+	live.Add(
+		NewProperty(`id`, TypeLong),
+		NewProperty(`name`, TypeKeyword),
+		NewProperty(`content`, TypeText),
+		NewProperty(`legacy`, TypeKeyword),
+		NewProperty(`address`, TypeObject).WithProperties(NewProperty(`city`, TypeKeyword)),
+	)
+
+	declared := &Mappings{}
+	declared.Add(
+		NewProperty(`id`, TypeUlong),
+		NewProperty(`name`, TypeKeyword).WithIgnoreAbove(256),
+		NewProperty(`content`, TypeText).WithFields(NewProperty(`keyword`, TypeKeyword)),
+		NewProperty(`status`, TypeByte),
+		NewProperty(`address`, TypeObject).WithProperties(
+			NewProperty(`city`, TypeKeyword),
+			NewProperty(`street`, TypeText),
+		),
+	)
+
+	liveSettings, _ := settingsFromIndex(base.JsonParam{"number_of_shards": "1", "refresh_interval": "1s"})
+	declaredSettings := (&Settings{NumberOfShards: 2}).WithRefreshInterval(`1s`)
+
+	diff := DiffSchema(declaredSettings, liveSettings, declared, live)
+	t.Logf("diff: %s", diff.Marshal())
+
+	if len(diff.Added) != 3 || len(diff.Updated) != 1 || len(diff.Changed) != 1 || len(diff.Removed) != 1 || len(diff.Settings) != 1 {
+		t.Fatalf("unexpected diff: %s", diff.Marshal())
+	}
+
+	if !diff.NeedReindex() {
+		t.Fatalf("want true, got false")
+	}
+
+	alter := diff.AlterMappings()
+	if _, ok := alter.Properties["id"]; ok {
+		t.Fatalf("changed field should not be altered")
+	}
+
+	t.Logf("alter: %s", alter.Marshal())
+}
+
+func TestMigrator_Run(t *testing.T) {
+	mappings := &Mappings{}
+	mappings.Add(
+		NewProperty(`id`, TypeUlong),
+		NewProperty(`name`, TypeKeyword),
+	)
+
+	migrator := NewMigrator(conn, `migrations`).Register(
+		&Migration{
+			Version: 1,
+			Name:    "create user_v1",
+			Steps: []MigrationStep{
+				CreateIndexStep(`user_v1`, &Settings{NumberOfShards: 1}, mappings),
+				SwapAliasStep(`user_alias`, ``, `user_v1`),
+			},
+		},
+		&Migration{
+			Version: 2,
+			Name:    "add status",
+			Steps: []MigrationStep{
+				AddFieldsStep(`user_v1`, (&Mappings{}).Add(NewProperty(`status`, TypeByte))),
+			},
+		},
+	)
+
+	applied, err := migrator.Run(time.Second * 10)
+	if err != nil {
+		t.Fatalf("want nil, got %s", err)
+	}
+
+	t.Logf("applied: %d", len(applied))
+
+	pending, err := migrator.Pending(time.Second * 3)
+	if err != nil {
+		t.Fatalf("want nil, got %s", err)
+	}
+
+	if len(pending) != 0 {
+		t.Fatalf("want 0, got %d", len(pending))
+	}
+}
+
+func TestMigration_Checksum(t *testing.T) {
+	build := func() *Migration {
+		mappings := &Mappings{}
+		mappings.Add(
+			NewProperty(`id`, TypeLong),
+			NewProperty(`name`, TypeKeyword),
+			NewProperty(`content`, TypeText).WithFields(NewProperty(`keyword`, TypeKeyword)),
+			NewProperty(`createdAt`, TypeDate).WithFormat(FormatUnixTime),
+		)
+
+		return &Migration{
+			Version: 1,
+			Name:    "create user_v1",
+			Steps: []MigrationStep{
+				CreateIndexStep(`user_v1`, &Settings{NumberOfShards: 1}, mappings),
+				AlterSettingsStep(`user_v1`, base.JsonParam{"number_of_replicas": 1, "refresh_interval": "30s", "max_result_window": 50000}),
+			},
+		}
+	}
+
+	checksum := build().Checksum()
+	for i := 0; i < 50; i++ {
+		if got := build().Checksum(); got != checksum {
+			t.Fatalf("want %s, got %s", checksum, got)
+		}
+	}
+}
+
+func TestConnection_ReindexWithAlias(t *testing.T) {
+	mappings := &Mappings{}
+	mappings.Add(
+		NewProperty(`id`, TypeUlong),
+		NewProperty(`name`, TypeKeyword),
+		NewProperty(`status`, TypeShort),
+	)
+
+	report, err := conn.ReindexWithAlias(time.Minute, &ReindexPlan{
+		ReadAlias: `user_alias`,
+		Settings:  &Settings{NumberOfShards: 1},
+		Mappings:  mappings,
+		Transform: func(id string, doc base.JsonParam) (base.JsonParam, bool) {
+			doc["status"] = doc.Int("status") + 1
+			return doc, true
+		},
+	})
+
+	if err != nil {
+		t.Fatalf("want nil, got %s", err)
+	}
+
+	t.Logf("report: %+v", report)
+}
+
+func TestConnection_ReindexWithAliasCleanup(t *testing.T) {
+	var deleted bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /_alias/user_alias":
+			_, _ = w.Write([]byte(`{"user_v1":{"aliases":{"user_alias":{}}}}`))
+		case "PUT /user_v2":
+			_, _ = w.Write([]byte(`{"acknowledged":true}`))
+		case "POST /_reindex":
+			if r.URL.Query().Get("wait_for_completion") != "false" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_, _ = w.Write([]byte(`{"task":"node1:1"}`))
+		case "GET /_tasks/node1:1":
+			_, _ = w.Write([]byte(`{"completed":true,"task":{"node":"node1","id":1},"response":{"total":3,"created":2,"failures":[]}}`))
+		case "GET /user_v1/_count":
+			_, _ = w.Write([]byte(`{"count":3}`))
+		case "GET /user_v2/_count":
+			_, _ = w.Write([]byte(`{"count":2}`))
+		case "DELETE /user_v2":
+			deleted = true
+			_, _ = w.Write([]byte(`{"acknowledged":true}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	c := New(Option{BaseUrl: server.URL})
+
+	report, err := c.ReindexWithAlias(time.Second, &ReindexPlan{ReadAlias: `user_alias`, TargetIndex: `user_v2`})
+	if !errors.Is(err, ErrReindexCountMismatch) || report.SourceCount != 3 {
+		t.Fatalf("want ErrReindexCountMismatch, got %v", err)
+	}
+
+	if !deleted {
+		t.Fatal("want target index deleted")
+	}
+}
+
+func TestConnection_ReindexWithAliasScript(t *testing.T) {
+	var swapped bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "PUT /user_v2":
+			_, _ = w.Write([]byte(`{"acknowledged":true}`))
+		case "POST /_reindex":
+			_, _ = w.Write([]byte(`{"task":"node1:1"}`))
+		case "GET /_tasks/node1:1":
+			_, _ = w.Write([]byte(`{"completed":true,"task":{"node":"node1","id":1},"response":{"total":3,"created":2,"noops":1,"failures":[]}}`))
+		case "GET /user_v1/_count":
+			_, _ = w.Write([]byte(`{"count":3}`))
+		case "GET /user_v2/_count":
+			_, _ = w.Write([]byte(`{"count":2}`))
+		case "POST /_aliases":
+			swapped = true
+			_, _ = w.Write([]byte(`{"acknowledged":true}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	c := New(Option{BaseUrl: server.URL})
+
+	report, err := c.ReindexWithAlias(time.Second, &ReindexPlan{
+		ReadAlias:   `user_alias`,
+		SourceIndex: `user_v1`,
+		TargetIndex: `user_v2`,
+		Script:      `if (ctx._source.status == 0) { ctx.op = 'noop' }`,
+	})
+	if err != nil || report.Dropped != 1 || !swapped {
+		t.Fatalf("unexpected report: %+v %v", report, err)
+	}
+}
+
+func TestConnection_ReindexWithAliasPollError(t *testing.T) {
+	var steps []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "PUT /user_v2":
+			_, _ = w.Write([]byte(`{"acknowledged":true}`))
+		case "POST /_reindex":
+			_, _ = w.Write([]byte(`{"task":"node1:1"}`))
+		case "GET /_tasks/node1:1":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case "POST /_tasks/node1:1/_cancel":
+			steps = append(steps, "cancel:"+r.URL.RawQuery)
+			_, _ = w.Write([]byte(`{"nodes":{}}`))
+		case "DELETE /user_v2":
+			steps = append(steps, "delete")
+			_, _ = w.Write([]byte(`{"acknowledged":true}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	c := New(Option{BaseUrl: server.URL})
+
+	_, err := c.ReindexWithAlias(time.Second, &ReindexPlan{ReadAlias: `user_alias`, SourceIndex: `user_v1`, TargetIndex: `user_v2`})
+	if err == nil {
+		t.Fatal("want poll error")
+	}
+
+	if strings.Join(steps, ",") != "cancel:wait_for_completion=true,delete" {
+		t.Fatalf("want task cancelled before target deleted, got %v", steps)
+	}
+}
+
+func TestAliasAction_MarshalJSON(t *testing.T) {
+	data, err := base.JsonMarshal([]AliasAction{
+		RemoveAlias(`user_v1`, `user_alias`),
+		AddAlias(`user_v2`, NewAlias(`user_alias`).WithWriteIndex(true).WithRouting(`1`)),
+		AddAlias(`user_v2`, NewAlias(`user_active`).WithFilter(AndCondition(Term(`status`, `1`)))),
+		RemoveIndex(`user_v0`),
+	})
+
+	if err != nil {
+		t.Fatalf("want nil, got %s", err)
+	}
+
+	t.Logf("actions: %s", data)
+
+	for _, want := range []string{
+		`"alias":"user_active"`,
+		`"is_write_index":true`,
+		`"filter":{"query_string":{"query":"(status:1)"}}`,
+		`{"remove_index":{"index":"user_v0"}}`,
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Fatalf("want %s in %s", want, data)
+		}
+	}
+}
+
+func TestConnection_AliasesUpdate(t *testing.T) {
+	ok, err := conn.AliasesUpdate(time.Second*3,
+		AddAlias(`user`, NewAlias(`user_alias`).WithWriteIndex(true)),
+	)
+	t.Logf("ok:%t err:%+v", ok, err)
+
+	indices, err := conn.AliasIndices(time.Second*3, `user_alias`)
+	t.Logf("indices:%v err:%+v", indices, err)
+}
+
+func TestConnection_IndexTemplate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/_index_template/logs":
+			_, _ = w.Write([]byte(`{"index_templates":[{"name":"logs","index_template":{"index_patterns":["logs-*"],"composed_of":["logs-mappings"],"priority":100,"template":{"settings":{"index":{"number_of_shards":"2","refresh_interval":"30s"}},"mappings":{"properties":{"message":{"type":"text"}}},"aliases":{"logs":{}}}}}]}`))
+		case "/_index_template/_simulate_index/logs-2026.10.17":
+			_, _ = w.Write([]byte(`{"template":{"settings":{"index":{"number_of_shards":"2"}},"mappings":{"properties":{"message":{"type":"text"}}},"aliases":{}},"overlapping":[{"name":"old","index_patterns":["logs-*"]}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	c := New(Option{BaseUrl: server.URL})
+
+	templates, err := c.IndexTemplateGet(time.Second, `logs`)
+	if err != nil {
+		t.Fatalf("want nil, got %s", err)
+	}
+
+	logs := templates["logs"]
+	if logs == nil || logs.Priority != 100 || logs.Template.Settings.NumberOfShards != 2 || logs.Template.Mappings.Properties["message"].FieldName() != "message" {
+		t.Fatalf("unexpected templates: %+v", templates)
+	}
+
+	simulated, err := c.IndexTemplateSimulate(time.Second, `logs-2026.10.17`)
+	if err != nil {
+		t.Fatalf("want nil, got %s", err)
+	}
+
+	if simulated.Template.Settings.NumberOfShards != 2 || len(simulated.Overlapping) != 1 {
+		t.Fatalf("unexpected simulated index: %+v", simulated)
+	}
+
+	t.Logf("template: %s", logs.Marshal())
+}
+
+func TestConnection_IndexLifecycle(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "HEAD /user,user_v1":
+		case "POST /user*/_close":
+			_, _ = w.Write([]byte(`{"acknowledged":true,"shards_acknowledged":true,"indices":{"user_v1":{"closed":true}}}`))
+		case "POST /user*/_forcemerge":
+			if r.URL.Query().Get("max_num_segments") != "1" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_, _ = w.Write([]byte(`{"_shards":{"total":2,"successful":1,"failed":1,"failures":[{"index":"user_v1","shard":0,"status":"INTERNAL_SERVER_ERROR","reason":{"type":"exception","reason":"merge failed"}}]}}`))
+		case "GET /user/_stats":
+			_, _ = w.Write([]byte(`{"_shards":{"total":2,"successful":2,"failed":0},"_all":{"primaries":{"docs":{"count":10}}},"indices":{"user_v1":{"uuid":"abc","primaries":{"docs":{"count":10,"deleted":1},"store":{"size_in_bytes":2048}}}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	c := New(Option{BaseUrl: server.URL})
+
+	exists, err := c.IndexExists(time.Second, Indices(`user`, `user_v1`))
+	if err != nil || !exists {
+		t.Fatalf("want true, got %t %v", exists, err)
+	}
+
+	exists, err = c.IndexExists(time.Second, `order`)
+	if err != nil || exists {
+		t.Fatalf("want false, got %t %v", exists, err)
+	}
+
+	closed, err := c.IndexClose(time.Second, `user*`)
+	if err != nil || !closed.Acknowledged || !closed.Indices["user_v1"].Closed {
+		t.Fatalf("unexpected close result: %+v %v", closed, err)
+	}
+
+	merged, err := c.IndexForceMerge(time.Second, `user*`, 1)
+	if err != nil || !merged.HasFailures() || merged.Shards.Failures[0].Reason.Reason != "merge failed" {
+		t.Fatalf("unexpected forcemerge result: %+v %v", merged, err)
+	}
+
+	stats, err := c.IndexStats(time.Second, `user`)
+	if err != nil || stats.Indices["user_v1"].Primaries.Docs.Count != 10 || stats.Indices["user_v1"].Primaries.Store.SizeInBytes != 2048 {
+		t.Fatalf("unexpected stats: %+v %v", stats, err)
+	}
+}
+
+func TestConnection_IndexRollover(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		switch r.URL.Path {
+		case "/logs/_rollover":
+			if r.URL.Query().Get("dry_run") != "true" || !strings.Contains(string(body), `"conditions":{"max_age":"7d","max_docs":1000}`) {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_, _ = w.Write([]byte(`{"acknowledged":false,"shards_acknowledged":false,"old_index":"logs-000001","new_index":"logs-000002","rolled_over":false,"dry_run":true,"conditions":{"[max_age: 7d]":false,"[max_docs: 1000]":true}}`))
+		case "/logs-000001/_shrink/logs-shrink":
+			if !strings.Contains(string(body), `"number_of_shards":1`) {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_, _ = w.Write([]byte(`{"acknowledged":true,"shards_acknowledged":true,"index":"logs-shrink"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	c := New(Option{BaseUrl: server.URL})
+
+	rr, err := c.IndexRolloverDryRun(time.Second, `logs`, ``, &Rollover{
+		Conditions: &RolloverConditions{MaxAge: "7d", MaxDocs: 1000},
+	})
+	if err != nil || !rr.DryRun || rr.NewIndex != "logs-000002" || !rr.Conditions["[max_docs: 1000]"] {
+		t.Fatalf("unexpected rollover result: %+v %v", rr, err)
+	}
+
+	sr, err := c.IndexShrink(time.Second, `logs-000001`, `logs-shrink`, &Settings{NumberOfShards: 1})
+	if err != nil || !sr.Acknowledged || sr.SourceIndex != "logs-000001" || sr.Index != "logs-shrink" {
+		t.Fatalf("unexpected shrink result: %+v %v", sr, err)
+	}
+}
+
+func TestIlmPolicy_Marshal(t *testing.T) {
+	policy := NewIlmPolicy().
+		WithPhase(PhaseHot, NewIlmPhase("").WithRollover(RolloverConditions{MaxAge: "7d", MaxPrimaryShardSize: "50gb"})).
+		WithPhase(PhaseWarm, NewIlmPhase("30d").WithShrink(1).WithForcemerge(1).WithReadonly()).
+		WithPhase(PhaseDelete, NewIlmPhase("90d").WithDelete())
+
+	data := string(policy.Marshal())
+	for _, want := range []string{
+		`"hot":{"actions":{"rollover":{"max_age":"7d","max_primary_shard_size":"50gb"}}}`,
+		`"warm":{"min_age":"30d","actions":{"readonly":{},"shrink":{"number_of_shards":1},"forcemerge":{"max_num_segments":1}}}`,
+		`"delete":{"min_age":"90d","actions":{"delete":{}}}`,
+	} {
+		if !strings.Contains(data, want) {
+			t.Fatalf("want %s in %s", want, data)
+		}
+	}
+
+	settings := (&Settings{NumberOfShards: 1}).WithLifecycle(`logs`, `logs_write`)
+	if string(settings.Marshal()) != `{"number_of_shards":1,"lifecycle":{"name":"logs","rollover_alias":"logs_write"}}` {
+		t.Fatalf("unexpected settings: %s", settings.Marshal())
+	}
+}
+
+func TestConnection_IlmExplain(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/logs-*/_ilm/explain":
+			_, _ = w.Write([]byte(`{"indices":{"logs-000001":{"index":"logs-000001","managed":true,"policy":"logs","phase":"hot","action":"rollover","step":"ERROR","failed_step":"check-rollover-ready","step_info":{"type":"illegal_argument_exception","reason":"rollover_alias is empty"}}}}`))
+		case "/_ilm/policy/logs":
+			_, _ = w.Write([]byte(`{"logs":{"version":2,"policy":{"phases":{"delete":{"min_age":"90d","actions":{"delete":{"delete_searchable_snapshot":true}}}}}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	c := New(Option{BaseUrl: server.URL})
+
+	explain, err := c.IlmExplain(time.Second, `logs-*`)
+	if err != nil {
+		t.Fatalf("want nil, got %s", err)
+	}
+
+	index := explain.Indices["logs-000001"]
+	if !index.IsError() || index.FailedStep != "check-rollover-ready" || index.StepInfo.String("type") != "illegal_argument_exception" {
+		t.Fatalf("unexpected explain: %+v", index)
+	}
+
+	policies, err := c.IlmPolicyGet(time.Second, `logs`)
+	if err != nil || policies["logs"].Phases[PhaseDelete].MinAge != "90d" {
+		t.Fatalf("unexpected policies: %+v %v", policies, err)
+	}
+}
+
+func TestConnection_DocsModify(t *testing.T) {
+	var seqNo, puts int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("routing") != "tenant1" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			if r.URL.Query().Has("refresh") {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_, _ = w.Write([]byte(`{"_index":"user","_id":"1","_seq_no":` + strconv.FormatInt(seqNo, 10) + `,"_primary_term":1,"found":true,"_source":{"count":1}}`))
+		case http.MethodPut:
+			puts++
+			// 第一次写入前文档被其他人修改
+			if puts == 1 {
+				seqNo++
+			}
+
+			if r.URL.Query().Get("refresh") != RefreshWaitFor {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			if r.URL.Query().Get("if_seq_no") != strconv.FormatInt(seqNo, 10) || r.URL.Query().Get("if_primary_term") != "1" {
+				w.WriteHeader(http.StatusConflict)
+				_, _ = w.Write([]byte(`{"error":{"type":"version_conflict_engine_exception"},"status":409}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"_index":"user","_id":"1","_version":3,"result":"updated"}`))
+		case http.MethodDelete:
+			w.WriteHeader(http.StatusConflict)
+		}
+	}))
+	defer server.Close()
+
+	c := New(Option{BaseUrl: server.URL})
+
+	res, err := c.DocsModify(time.Second, `user`, `1`, 1, func(doc base.JsonParam) (base.JsonParam, error) {
+		doc["count"] = doc.Int64("count") + 1
+		return doc, nil
+	}, NewDocOptions().Routing(`tenant1`).Refresh(RefreshWaitFor))
+	if err != nil || res.Result != "updated" || puts != 2 {
+		t.Fatalf("unexpected modify result: %+v %v puts:%d", res, err, puts)
+	}
+
+	_, err = c.DocsDelete(time.Second, `user`, `1`, NewDocOptions().IfSeqNo(0, 1).Routing(`tenant1`))
+	if !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("want ErrVersionConflict, got %v", err)
+	}
+}
+
+func TestConnection_DocsInsertIfSeqNo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		switch r.Method + " " + r.URL.Path {
+		case "PUT /user/_doc/1":
+			if r.URL.RawQuery != "if_primary_term=1&if_seq_no=7" || string(body) != `{"name":"a"}` {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_, _ = w.Write([]byte(`{"_index":"user","_id":"1","_version":2,"result":"updated"}`))
+		case "POST /user/_create/2":
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"_index":"user","_id":"2","_version":1,"result":"created"}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	c := New(Option{BaseUrl: server.URL})
+
+	res, err := c.DocsInsert(time.Second, `user`, base.JsonParam{"_id": "1", "name": "a"}, NewDocOptions().IfSeqNo(7, 1))
+	if err != nil || res.Result != results.ResultUpdated {
+		t.Fatalf("unexpected insert result: %+v %v", res, err)
+	}
+
+	res, err = c.DocsInsert(time.Second, `user`, base.JsonParam{"_id": "2", "name": "b"}, NewDocOptions().Refresh(RefreshWaitFor))
+	if err != nil || res.Result != results.ResultCreated {
+		t.Fatalf("unexpected insert result: %+v %v", res, err)
+	}
+}
+
+func TestDocOptions_Path(t *testing.T) {
+	opts := NewDocOptions().
+		Refresh(RefreshWaitFor).
+		Routing(`tenant 1`).
+		Timeout(time.Second * 2).
+		WaitForActiveShards(ActiveShardsAll).
+		Pipeline(`user`)
+
+	path := withPath(`/user/_doc/1`, []*DocOptions{opts, nil, NewDocOptions().Routing(`tenant2`)})
+	if path != `/user/_doc/1?pipeline=user&refresh=wait_for&routing=tenant2&timeout=2000ms&wait_for_active_shards=all` {
+		t.Fatalf("unexpected path: %s", path)
+	}
+
+	path = withPath(`/user/_doc/1?version=2`, []*DocOptions{NewDocOptions().Routing(`tenant 1`)})
+	if path != `/user/_doc/1?version=2&routing=tenant+1` {
+		t.Fatalf("unexpected path: %s", path)
+	}
+
+	if withPath(`/user/_doc/1`, nil) != `/user/_doc/1` {
+		t.Fatal("want path without query")
+	}
+}
+
+func TestConnection_DocsUpdateWith(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		want := `{"upsert":{"count":1},"script":{"source":"ctx._source.count += params.n","lang":"painless","params":{"n":2}}}`
+		if r.URL.Path != "/user/_update/1" || string(body) != want || r.URL.RawQuery != "_source=true&retry_on_conflict=3" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write(body)
+			return
+		}
+		_, _ = w.Write([]byte(`{"_index":"user","_id":"1","_version":2,"result":"noop","get":{"found":true,"_source":{"count":3}}}`))
+	}))
+	defer server.Close()
+
+	c := New(Option{BaseUrl: server.URL})
+
+	res, err := c.DocsUpdateScript(time.Second, `user`, `1`,
+		NewScript(`ctx._source.count += params.n`).WithParams(base.JsonParam{"n": 2}),
+		base.JsonParam{"count": 1},
+		NewDocOptions().RetryOnConflict(3).Source(true),
+	)
+	if err != nil || !res.IsNoop() || res.Source().Int64("count") != 3 {
+		t.Fatalf("unexpected update result: %+v %v", res, err)
+	}
+
+	data := string(NewUpdate().WithDoc(base.JsonParam{"name": "a"}).WithDocAsUpsert().WithDetectNoop(false).Marshal())
+	if data != `{"doc":{"name":"a"},"doc_as_upsert":true,"detect_noop":false}` {
+		t.Fatalf("unexpected update body: %s", data)
+	}
+}
+
+func TestQuery_UpdateByQuery(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		switch r.URL.Path {
+		case "/user/_update_by_query":
+			if r.URL.RawQuery != "conflicts=proceed&requests_per_second=500&slices=auto" || !strings.Contains(string(body), `,"script":{"source":"ctx._source.status = params.status","lang":"painless","params":{"status":0}}}`) {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write(body)
+				return
+			}
+			_, _ = w.Write([]byte(`{"took":12,"total":3,"updated":2,"version_conflicts":1,"failures":[]}`))
+		case "/user/_delete_by_query":
+			if r.URL.RawQuery != "wait_for_completion=false" || !strings.Contains(string(body), `"max_docs":100}`) {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write(body)
+				return
+			}
+			_, _ = w.Write([]byte(`{"task":"node1:123"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	c := New(Option{BaseUrl: server.URL})
+	query := &Query{}
+	query.From(`user`).Where(AndCondition(Lt("lastLoginTime", "1666666666")))
+
+	res, err := query.UpdateByQuery(time.Second, c,
+		NewScript(`ctx._source.status = params.status`).WithParams(base.JsonParam{"status": 0}),
+		NewDocOptions().Conflicts(ConflictsProceed).Slices(0).RequestsPerSecond(500),
+	)
+	if err != nil || res.Updated != 2 || res.VersionConflicts != 1 {
+		t.Fatalf("unexpected update by query result: %+v %v", res, err)
+	}
+
+	res, err = query.Limit(100).DeleteByQuery(time.Second, c, NewDocOptions().Async())
+	if err != nil || res.Task != "node1:123" {
+		t.Fatalf("unexpected delete by query result: %+v %v", res, err)
+	}
+}
+
+func TestConnection_WaitForTask(t *testing.T) {
+	var polls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/_tasks/node1:123":
+			polls++
+			if polls < 3 {
+				_, _ = w.Write([]byte(`{"completed":false,"task":{"node":"node1","id":123,"action":"indices:data/write/reindex","status":{"total":10,"created":` + strconv.Itoa(polls*3) + `}}}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"completed":true,"task":{"node":"node1","id":123,"action":"indices:data/write/reindex","status":{"total":10,"created":10}},"response":{"total":10,"created":10,"failures":[]}}`))
+		case "/_tasks":
+			if r.URL.Query().Get("actions") != "*reindex,*byquery" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_, _ = w.Write([]byte(`{"nodes":{"node1":{"name":"es1","tasks":{"node1:123":{"node":"node1","id":123,"action":"indices:data/write/reindex","cancellable":true}}}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	c := New(Option{BaseUrl: server.URL})
+
+	list, err := c.TaskList(time.Second, `*reindex`, `*byquery`)
+	if err != nil || len(list.Tasks()) != 1 || list.Tasks()[0].TaskId() != "node1:123" {
+		t.Fatalf("unexpected task list: %+v %v", list, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	res, err := c.WaitForTask(ctx, `node1:123`, time.Millisecond*10)
+	if err != nil || !res.Completed || res.Response.Created != 10 || polls != 3 {
+		t.Fatalf("unexpected task result: %+v %v polls:%d", res, err, polls)
+	}
+
+	res, err = c.WaitForTask(ctx, `node1:123`, 0)
+	if err != nil || !res.Completed {
+		t.Fatalf("unexpected task result: %+v %v", res, err)
+	}
+}
+
+func TestReindex_Marshal(t *testing.T) {
+	source := &Query{}
+	source.From(`user_v1`).
+		Select("id", "name").
+		Where(AndCondition(Term("status", "1"))).
+		Limit(1000)
+
+	reindex := NewReindex(source, `user_v2`).
+		WithOpType(OpTypeCreate).
+		WithConflicts(ConflictsProceed).
+		WithBatchSize(500).
+		WithScript(NewScript(`ctx._source.remove("password")`)).
+		WithRemote(&ReindexRemote{Host: "http://old-cluster:9200", Username: "elastic"})
+
+	data := string(reindex.Marshal())
+	for _, want := range []string{
+		`"conflicts":"proceed","max_docs":1000`,
+		`"source":{"index":"user_v1","query":{"query_string":{"query":`,
+		`"_source":["id","name"],"size":500,"remote":{"host":"http://old-cluster:9200","username":"elastic"}}`,
+		`"dest":{"index":"user_v2","op_type":"create"}`,
+		`"script":{"source":"ctx._source.remove(\"password\")","lang":"painless"}`,
+	} {
+		if !strings.Contains(data, want) {
+			t.Fatalf("want %s in %s", want, data)
+		}
+	}
+
+	data = string(NewReindex((&Query{}).From(`user_v1`), `user_v2`).Marshal())
+	if data != `{"source":{"index":"user_v1"},"dest":{"index":"user_v2"}}` {
+		t.Fatalf("unexpected reindex body: %s", data)
+	}
+}
+
+func TestConnection_DocsExists(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "HEAD /user/_doc/1":
+		case "GET /user/_source/1":
+			if r.URL.RawQuery != "_source_excludes=password&_source_includes=id%2Cname" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_, _ = w.Write([]byte(`{"id":1,"name":"a"}`))
+		case "GET /user/_doc/1":
+			if r.URL.Query().Get("stored_fields") != "tags" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_, _ = w.Write([]byte(`{"_index":"user","_id":"1","found":true,"fields":{"tags":["a","b"]}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	c := New(Option{BaseUrl: server.URL})
+
+	exists, err := c.DocsExists(time.Second, `user`, `1`)
+	if err != nil || !exists {
+		t.Fatalf("want true, got %t %v", exists, err)
+	}
+
+	exists, err = c.DocsExists(time.Second, `user`, `2`)
+	if err != nil || exists {
+		t.Fatalf("want false, got %t %v", exists, err)
+	}
+
+	source, err := c.DocsSource(time.Second, `user`, `1`, NewDocOptions().SourceIncludes("id", "name").SourceExcludes("password"))
+	if err != nil || source.String("name") != "a" {
+		t.Fatalf("unexpected source: %+v %v", source, err)
+	}
+
+	if _, err = c.DocsSource(time.Second, `user`, `2`); !errors.Is(err, ErrDocumentNotFound) {
+		t.Fatalf("want ErrDocumentNotFound, got %v", err)
+	}
+
+	doc, err := c.DocsGet(time.Second, `user`, `1`, NewDocOptions().StoredFields("tags"))
+	if err != nil || !doc.Found || doc.Fields["tags"] == nil {
+		t.Fatalf("unexpected document: %+v %v", doc, err)
+	}
+}
+
+func TestMigrator_Unlock(t *testing.T) {
+	var released string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "HEAD /migrations":
+		case "POST /migrations/_create/lock":
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"_index":"migrations","_id":"lock","_version":1,"_seq_no":5,"_primary_term":2,"result":"created"}`))
+		case "GET /migrations/_search":
+			_, _ = w.Write([]byte(`{"hits":{"total":{"value":0},"hits":[]}}`))
+		case "DELETE /migrations/_doc/lock":
+			released = r.URL.RawQuery
+			// 锁已被其他执行者接管
+			w.WriteHeader(http.StatusConflict)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	applied, err := NewMigrator(New(Option{BaseUrl: server.URL}), `migrations`).Run(time.Second)
+	if err != nil || len(applied) != 0 {
+		t.Fatalf("want nil, got %v", err)
+	}
+
+	if released != "if_primary_term=2&if_seq_no=5" {
+		t.Fatalf("unexpected release query: %s", released)
+	}
+}
+
+func TestMigrator_Renew(t *testing.T) {
+	var (
+		renewals int
+		steps    []string
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "HEAD /migrations":
+		case "POST /migrations/_create/lock":
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"_index":"migrations","_id":"lock","_seq_no":5,"_primary_term":2,"result":"created"}`))
+		case "GET /migrations/_search":
+			_, _ = w.Write([]byte(`{"hits":{"total":{"value":0},"hits":[]}}`))
+		case "PUT /migrations/_doc/lock":
+			renewals++
+			// 第二次续期前锁被其他执行者接管
+			if renewals > 1 || r.URL.Query().Get("if_seq_no") != "5" {
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+			_, _ = w.Write([]byte(`{"_index":"migrations","_id":"lock","_seq_no":6,"_primary_term":2,"result":"updated"}`))
+		case "DELETE /migrations/_doc/lock":
+			steps = append(steps, "unlock")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	step := func(name string) MigrationStep {
+		return FuncStep(name, 1, func(timeout time.Duration, conn *Connection) error {
+			steps = append(steps, name)
+			return nil
+		})
+	}
+
+	migrator := NewMigrator(New(Option{BaseUrl: server.URL}), `migrations`).Register(&Migration{
+		Version: 1,
+		Name:    "two steps",
+		Steps:   []MigrationStep{step("first"), step("second")},
+	})
+
+	_, err := migrator.Run(time.Second)
+	if !errors.Is(err, ErrMigrationLocked) {
+		t.Fatalf("want ErrMigrationLocked, got %v", err)
+	}
+
+	if strings.Join(steps, ",") != "first" {
+		t.Fatalf("want only first step applied and lock not released, got %v", steps)
+	}
+}
@@ -1,15 +1,15 @@
 module github.com/grpc-boot/elastic
 
-go 1.17
+go 1.18
 
 require (
 	github.com/grpc-boot/base v1.2.15
+	github.com/json-iterator/go v1.1.12
 	go.uber.org/zap v1.20.0
 )
 
 require (
 	github.com/golang/protobuf v1.5.2 // indirect
-	github.com/json-iterator/go v1.1.12 // indirect
 	github.com/modern-go/concurrent v0.0.0-20180228061459-e0a39a4cb421 // indirect
 	github.com/modern-go/reflect2 v1.0.2 // indirect
 	go.uber.org/atomic v1.9.0 // indirect
@@ -0,0 +1,204 @@
+package elastic
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/grpc-boot/elastic/results"
+
+	"github.com/grpc-boot/base"
+)
+
+const (
+	PhaseHot    = `hot`
+	PhaseWarm   = `warm`
+	PhaseCold   = `cold`
+	PhaseFrozen = `frozen`
+	PhaseDelete = `delete`
+)
+
+// IlmPolicy index lifecycle management policy
+// link: https://www.elastic.co/guide/en/elasticsearch/reference/current/ilm-index-lifecycle.html
+type IlmPolicy struct {
+	Phases map[string]*IlmPhase   `json:"phases"`
+	Meta   map[string]interface{} `json:"_meta,omitempty"`
+}
+
+func NewIlmPolicy() *IlmPolicy {
+	return &IlmPolicy{Phases: map[string]*IlmPhase{}}
+}
+
+func (ip *IlmPolicy) WithPhase(name string, phase *IlmPhase) *IlmPolicy {
+	if ip.Phases == nil {
+		ip.Phases = map[string]*IlmPhase{}
+	}
+
+	ip.Phases[name] = phase
+	return ip
+}
+
+func (ip *IlmPolicy) Marshal() []byte {
+	data, _ := base.JsonMarshal(ip)
+	return data
+}
+
+// IlmPhase index enters phase when its age reaches MinAge
+type IlmPhase struct {
+	MinAge  string     `json:"min_age,omitempty"`
+	Actions IlmActions `json:"actions"`
+}
+
+// IlmActions
+// link: https://www.elastic.co/guide/en/elasticsearch/reference/current/ilm-actions.html
+type IlmActions struct {
+	Rollover    *RolloverConditions `json:"rollover,omitempty"`
+	SetPriority *IlmSetPriority     `json:"set_priority,omitempty"`
+	Allocate    *IlmAllocate        `json:"allocate,omitempty"`
+	Readonly    *struct{}           `json:"readonly,omitempty"`
+	Shrink      *IlmShrink          `json:"shrink,omitempty"`
+	Forcemerge  *IlmForcemerge      `json:"forcemerge,omitempty"`
+	Delete      *IlmDelete          `json:"delete,omitempty"`
+}
+
+type IlmSetPriority struct {
+	Priority int `json:"priority"`
+}
+
+type IlmAllocate struct {
+	NumberOfReplicas *int              `json:"number_of_replicas,omitempty"`
+	Include          map[string]string `json:"include,omitempty"`
+	Exclude          map[string]string `json:"exclude,omitempty"`
+	Require          map[string]string `json:"require,omitempty"`
+}
+
+type IlmShrink struct {
+	NumberOfShards      int    `json:"number_of_shards,omitempty"`
+	MaxPrimaryShardSize string `json:"max_primary_shard_size,omitempty"`
+}
+
+type IlmForcemerge struct {
+	MaxNumSegments int `json:"max_num_segments"`
+}
+
+type IlmDelete struct {
+	DeleteSearchableSnapshot *bool `json:"delete_searchable_snapshot,omitempty"`
+}
+
+func NewIlmPhase(minAge string) *IlmPhase {
+	return &IlmPhase{MinAge: minAge}
+}
+
+func (ip *IlmPhase) WithRollover(conditions RolloverConditions) *IlmPhase {
+	ip.Actions.Rollover = &conditions
+	return ip
+}
+
+func (ip *IlmPhase) WithSetPriority(priority int) *IlmPhase {
+	ip.Actions.SetPriority = &IlmSetPriority{Priority: priority}
+	return ip
+}
+
+func (ip *IlmPhase) WithAllocate(allocate IlmAllocate) *IlmPhase {
+	ip.Actions.Allocate = &allocate
+	return ip
+}
+
+func (ip *IlmPhase) WithReadonly() *IlmPhase {
+	ip.Actions.Readonly = &struct{}{}
+	return ip
+}
+
+func (ip *IlmPhase) WithShrink(numberOfShards int) *IlmPhase {
+	ip.Actions.Shrink = &IlmShrink{NumberOfShards: numberOfShards}
+	return ip
+}
+
+func (ip *IlmPhase) WithForcemerge(maxNumSegments int) *IlmPhase {
+	ip.Actions.Forcemerge = &IlmForcemerge{MaxNumSegments: maxNumSegments}
+	return ip
+}
+
+func (ip *IlmPhase) WithDelete() *IlmPhase {
+	ip.Actions.Delete = &IlmDelete{}
+	return ip
+}
+
+// IlmPolicyPut create or update policy, version of policy is increased by server
+// link: https://www.elastic.co/guide/en/elasticsearch/reference/current/ilm-put-lifecycle.html
+func (c *Connection) IlmPolicyPut(timeout time.Duration, name string, policy *IlmPolicy) (ok bool, err error) {
+	param, err := base.JsonMarshal(map[string]*IlmPolicy{"policy": policy})
+	if err != nil {
+		return
+	}
+
+	resp, err := c.Put(timeout, "/_ilm/policy/"+name, base.Bytes2String(param))
+	if err != nil {
+		return
+	}
+
+	if !resp.IsOk() {
+		return false, resp.Error()
+	}
+
+	return true, nil
+}
+
+// IlmPolicyGet empty name returns all policies
+// link: https://www.elastic.co/guide/en/elasticsearch/reference/current/ilm-get-lifecycle.html
+func (c *Connection) IlmPolicyGet(timeout time.Duration, name string) (map[string]*IlmPolicy, error) {
+	var result map[string]struct {
+		Version int64      `json:"version"`
+		Policy  *IlmPolicy `json:"policy"`
+	}
+
+	resp, err := c.decode(timeout, http.MethodGet, "/_ilm/policy/"+name, "", &result)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Is(http.StatusNotFound) {
+		return map[string]*IlmPolicy{}, nil
+	}
+
+	if !resp.IsOk() {
+		return nil, resp.Error()
+	}
+
+	policies := make(map[string]*IlmPolicy, len(result))
+	for policyName, item := range result {
+		policies[policyName] = item.Policy
+	}
+
+	return policies, nil
+}
+
+// IlmPolicyDelete policy in use by any index can not be deleted
+// link: https://www.elastic.co/guide/en/elasticsearch/reference/current/ilm-delete-lifecycle.html
+func (c *Connection) IlmPolicyDelete(timeout time.Duration, name string) (ok bool, err error) {
+	resp, err := c.Delete(timeout, "/_ilm/policy/"+name, "")
+	if err != nil {
+		return
+	}
+
+	if !resp.IsOk() {
+		return false, resp.Error()
+	}
+
+	return true, nil
+}
+
+// IlmExplain current lifecycle phase, action and step of index, index can be wildcard pattern or comma-separated list
+// link: https://www.elastic.co/guide/en/elasticsearch/reference/current/ilm-explain-lifecycle.html
+func (c *Connection) IlmExplain(timeout time.Duration, index string) (*results.IlmExplainResult, error) {
+	result := &results.IlmExplainResult{}
+	resp, err := c.decode(timeout, http.MethodGet, "/"+index+"/_ilm/explain", "", result)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.IsOk() {
+		return result, nil
+	}
+
+	return nil, resp.Error()
+}
@@ -0,0 +1,119 @@
+package elastic
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grpc-boot/elastic/results"
+)
+
+// Indices join index names into comma-separated list
+func Indices(names ...string) string {
+	return strings.Join(names, ",")
+}
+
+// IndexExists index can be alias, wildcard pattern or comma-separated list
+// link: https://www.elastic.co/guide/en/elasticsearch/reference/current/indices-exists.html
+func (c *Connection) IndexExists(timeout time.Duration, index string) (exists bool, err error) {
+	resp, err := c.Head(timeout, "/"+index)
+	if err != nil {
+		return
+	}
+
+	if resp.IsOk() {
+		return true, nil
+	}
+
+	if resp.Is(http.StatusNotFound) {
+		return false, nil
+	}
+
+	return false, resp.Error()
+}
+
+// IndexOpen
+// link: https://www.elastic.co/guide/en/elasticsearch/reference/current/indices-open-close.html
+func (c *Connection) IndexOpen(timeout time.Duration, index string) (*results.AcknowledgedResult, error) {
+	return c.acknowledged(timeout, http.MethodPost, "/"+index+"/_open", "")
+}
+
+// IndexClose
+// link: https://www.elastic.co/guide/en/elasticsearch/reference/current/indices-close.html
+func (c *Connection) IndexClose(timeout time.Duration, index string) (*results.AcknowledgedResult, error) {
+	return c.acknowledged(timeout, http.MethodPost, "/"+index+"/_close", "")
+}
+
+// IndexRefresh
+// link: https://www.elastic.co/guide/en/elasticsearch/reference/current/indices-refresh.html
+func (c *Connection) IndexRefresh(timeout time.Duration, index string) (*results.ShardsResult, error) {
+	return c.shards(timeout, "/"+index+"/_refresh")
+}
+
+// IndexFlush
+// link: https://www.elastic.co/guide/en/elasticsearch/reference/current/indices-flush.html
+func (c *Connection) IndexFlush(timeout time.Duration, index string) (*results.ShardsResult, error) {
+	return c.shards(timeout, "/"+index+"/_flush")
+}
+
+// IndexForceMerge maxNumSegments less than 1 lets server decide, forcemerge can take long, use large timeout
+// link: https://www.elastic.co/guide/en/elasticsearch/reference/current/indices-forcemerge.html
+func (c *Connection) IndexForceMerge(timeout time.Duration, index string, maxNumSegments int) (*results.ShardsResult, error) {
+	path := "/" + index + "/_forcemerge"
+	if maxNumSegments > 0 {
+		path += "?max_num_segments=" + strconv.Itoa(maxNumSegments)
+	}
+
+	return c.shards(timeout, path)
+}
+
+// IndexClearCache
+// link: https://www.elastic.co/guide/en/elasticsearch/reference/current/indices-clearcache.html
+func (c *Connection) IndexClearCache(timeout time.Duration, index string) (*results.ShardsResult, error) {
+	return c.shards(timeout, "/"+index+"/_cache/clear")
+}
+
+// IndexStats
+// link: https://www.elastic.co/guide/en/elasticsearch/reference/current/indices-stats.html
+func (c *Connection) IndexStats(timeout time.Duration, index string) (*results.IndexStatsResult, error) {
+	result := &results.IndexStatsResult{}
+	resp, err := c.decode(timeout, http.MethodGet, "/"+index+"/_stats", "", result)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.IsOk() {
+		return result, nil
+	}
+
+	return nil, resp.Error()
+}
+
+func (c *Connection) shards(timeout time.Duration, path string) (*results.ShardsResult, error) {
+	result := &results.ShardsResult{}
+	resp, err := c.decode(timeout, http.MethodPost, path, "", result)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.IsOk() {
+		return result, nil
+	}
+
+	return nil, resp.Error()
+}
+
+func (c *Connection) acknowledged(timeout time.Duration, method, path string, params string) (*results.AcknowledgedResult, error) {
+	result := &results.AcknowledgedResult{}
+	resp, err := c.decode(timeout, method, path, params, result)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.IsOk() {
+		return result, nil
+	}
+
+	return nil, resp.Error()
+}
@@ -0,0 +1,196 @@
+package elastic
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	ClassSearch = `search`
+	ClassBulk   = `bulk`
+	ClassAdmin  = `admin`
+)
+
+var (
+	ErrLimited = errors.New("request limited")
+)
+
+// LimitOption budget of an operation class
+type LimitOption struct {
+	// Rate requests per second, 0 unlimited
+	Rate  float64 `json:"rate" yaml:"rate"`
+	Burst int     `json:"burst" yaml:"burst"`
+	// MaxInFlight max concurrent requests, 0 unlimited
+	MaxInFlight int `json:"maxInFlight" yaml:"maxInFlight"`
+	// FailFast return ErrLimited at once instead of waiting until budget available or timeout
+	FailFast bool `json:"failFast" yaml:"failFast"`
+}
+
+type limiter struct {
+	mutex    sync.Mutex
+	rate     float64
+	burst    float64
+	tokens   float64
+	last     time.Time
+	inFlight chan struct{}
+	failFast bool
+}
+
+func newLimiter(opt LimitOption) *limiter {
+	if opt.Rate <= 0 && opt.MaxInFlight < 1 {
+		return nil
+	}
+
+	l := &limiter{
+		rate:     opt.Rate,
+		burst:    float64(opt.Burst),
+		failFast: opt.FailFast,
+		last:     time.Now(),
+	}
+
+	if l.burst < 1 {
+		l.burst = 1
+	}
+	l.tokens = l.burst
+
+	if opt.MaxInFlight > 0 {
+		l.inFlight = make(chan struct{}, opt.MaxInFlight)
+	}
+
+	return l
+}
+
+func newLimiters(option *Option) map[string]*limiter {
+	limiters := make(map[string]*limiter, 3)
+
+	for class, opt := range map[string]LimitOption{
+		ClassSearch: option.SearchLimit,
+		ClassBulk:   option.BulkLimit,
+		ClassAdmin:  option.AdminLimit,
+	} {
+		if l := newLimiter(opt); l != nil {
+			limiters[class] = l
+		}
+	}
+
+	return limiters
+}
+
+// classify 按路径区分请求类别，文档读写不受限制
+func classify(path string) string {
+	if index := strings.IndexByte(path, '?'); index > -1 {
+		path = path[:index]
+	}
+
+	switch {
+	case strings.Contains(path, "/_bulk"),
+		strings.Contains(path, "/_update_by_query"),
+		strings.Contains(path, "/_delete_by_query"),
+		strings.Contains(path, "/_reindex"):
+		return ClassBulk
+	case strings.Contains(path, "/_search"),
+		strings.Contains(path, "/_msearch"),
+		strings.Contains(path, "/_count"),
+		strings.Contains(path, "/_mget"),
+		strings.Contains(path, "/_sql"):
+		return ClassSearch
+	case strings.Contains(path, "/_doc"),
+		strings.Contains(path, "/_create/"),
+		strings.Contains(path, "/_update/"),
+		strings.Contains(path, "/_source/"):
+		return ""
+	}
+
+	return ClassAdmin
+}
+
+func (l *limiter) acquire(ctx context.Context) (release func(), err error) {
+	if l == nil {
+		return func() {}, nil
+	}
+
+	if err = l.wait(ctx); err != nil {
+		return nil, err
+	}
+
+	if l.inFlight == nil {
+		return func() {}, nil
+	}
+
+	// 并发数拒绝时归还已取得的令牌，避免令牌消耗快于限速
+	if l.failFast {
+		select {
+		case l.inFlight <- struct{}{}:
+		default:
+			l.refund()
+			return nil, ErrLimited
+		}
+	} else {
+		select {
+		case l.inFlight <- struct{}{}:
+		case <-ctx.Done():
+			l.refund()
+			return nil, ErrLimited
+		}
+	}
+
+	return func() { <-l.inFlight }, nil
+}
+
+// wait 令牌桶，令牌不足时预占令牌并等待补充
+func (l *limiter) wait(ctx context.Context) error {
+	if l.rate <= 0 {
+		return nil
+	}
+
+	l.mutex.Lock()
+
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+
+	if l.tokens >= 1 {
+		l.tokens--
+		l.mutex.Unlock()
+		return nil
+	}
+
+	delay := time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+	if deadline, ok := ctx.Deadline(); l.failFast || (ok && deadline.Sub(now) < delay) {
+		l.mutex.Unlock()
+		return ErrLimited
+	}
+
+	l.tokens--
+	l.mutex.Unlock()
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		l.refund()
+		return ErrLimited
+	}
+}
+
+func (l *limiter) refund() {
+	if l.rate <= 0 {
+		return
+	}
+
+	l.mutex.Lock()
+	l.tokens++
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.mutex.Unlock()
+}
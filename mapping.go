@@ -17,6 +17,22 @@ const (
 	TypeDate    = `date`
 	TypeIp      = `ip`
 	TypeVersion = `version`
+	TypeObject  = `object`
+	TypeNested  = `nested`
+
+	TypeHalfFloat       = `half_float`
+	TypeScaledFloat     = `scaled_float`
+	TypeWildcard        = `wildcard`
+	TypeConstantKeyword = `constant_keyword`
+	TypeMatchOnlyText   = `match_only_text`
+	TypeDateNanos       = `date_nanos`
+	TypeGeoPoint        = `geo_point`
+	TypeGeoShape        = `geo_shape`
+	TypeDenseVector     = `dense_vector`
+	TypeCompletion      = `completion`
+	TypeFlattened       = `flattened`
+	TypeJoin            = `join`
+	TypeAlias           = `alias`
 )
 
 const (
@@ -27,8 +43,34 @@ const (
 	FormatTimestampMilliSecond = `epoch_millis`
 )
 
+const (
+	DynamicTrue    = `true`
+	DynamicFalse   = `false`
+	DynamicStrict  = `strict`
+	DynamicRuntime = `runtime`
+)
+
+// Mappings
+// link: https://www.elastic.co/guide/en/elasticsearch/reference/current/mapping.html
 type Mappings struct {
-	Properties map[string]*Property `json:"properties"`
+	Dynamic          string                 `json:"dynamic,omitempty"`
+	DateDetection    *bool                  `json:"date_detection,omitempty"`
+	NumericDetection *bool                  `json:"numeric_detection,omitempty"`
+	DynamicTemplates DynamicTemplates       `json:"dynamic_templates,omitempty"`
+	Source           *SourceField           `json:"_source,omitempty"`
+	Routing          *RoutingField          `json:"_routing,omitempty"`
+	Meta             map[string]interface{} `json:"_meta,omitempty"`
+	Properties       map[string]*Property   `json:"properties,omitempty"`
+}
+
+type SourceField struct {
+	Enabled  *bool    `json:"enabled,omitempty"`
+	Includes []string `json:"includes,omitempty"`
+	Excludes []string `json:"excludes,omitempty"`
+}
+
+type RoutingField struct {
+	Required bool `json:"required"`
 }
 
 func (m *Mappings) Add(properties ...*Property) *Mappings {
@@ -43,15 +85,115 @@ func (m *Mappings) Add(properties ...*Property) *Mappings {
 	return m
 }
 
+// WithDynamic one of DynamicTrue, DynamicFalse, DynamicStrict and DynamicRuntime
+func (m *Mappings) WithDynamic(dynamic string) *Mappings {
+	m.Dynamic = dynamic
+	return m
+}
+
+func (m *Mappings) WithDateDetection(detection bool) *Mappings {
+	m.DateDetection = &detection
+	return m
+}
+
+func (m *Mappings) WithNumericDetection(detection bool) *Mappings {
+	m.NumericDetection = &detection
+	return m
+}
+
+func (m *Mappings) AddDynamicTemplates(templates ...*DynamicTemplate) *Mappings {
+	m.DynamicTemplates = append(m.DynamicTemplates, templates...)
+	return m
+}
+
+func (m *Mappings) WithSourceEnabled(enabled bool) *Mappings {
+	if m.Source == nil {
+		m.Source = &SourceField{}
+	}
+
+	m.Source.Enabled = &enabled
+	return m
+}
+
+func (m *Mappings) WithSourceIncludes(fields ...string) *Mappings {
+	if m.Source == nil {
+		m.Source = &SourceField{}
+	}
+
+	m.Source.Includes = fields
+	return m
+}
+
+func (m *Mappings) WithSourceExcludes(fields ...string) *Mappings {
+	if m.Source == nil {
+		m.Source = &SourceField{}
+	}
+
+	m.Source.Excludes = fields
+	return m
+}
+
+func (m *Mappings) WithRoutingRequired(required bool) *Mappings {
+	m.Routing = &RoutingField{Required: required}
+	return m
+}
+
+func (m *Mappings) WithMeta(key string, value interface{}) *Mappings {
+	if m.Meta == nil {
+		m.Meta = make(map[string]interface{})
+	}
+
+	m.Meta[key] = value
+	return m
+}
+
+// fillNames 反序列化后补全字段名
+func (m *Mappings) fillNames() {
+	fillPropertyNames(m.Properties)
+
+	for _, dt := range m.DynamicTemplates {
+		if dt.Mapping != nil {
+			fillPropertyNames(dt.Mapping.Properties)
+			fillPropertyNames(dt.Mapping.Fields)
+		}
+	}
+}
+
+func fillPropertyNames(properties map[string]*Property) {
+	for name, prop := range properties {
+		prop.n = name
+		fillPropertyNames(prop.Properties)
+		fillPropertyNames(prop.Fields)
+	}
+}
+
 func (m *Mappings) Marshal() []byte {
 	data, _ := base.JsonMarshal(m)
 	return data
 }
 
+// Property
+// link: https://www.elastic.co/guide/en/elasticsearch/reference/current/mapping-params.html
 type Property struct {
-	n      string
-	Type   string `json:"type"`
-	Format string `json:"format,omitempty"`
+	n              string
+	Type           string                 `json:"type,omitempty"`
+	Format         string                 `json:"format,omitempty"`
+	Analyzer       string                 `json:"analyzer,omitempty"`
+	SearchAnalyzer string                 `json:"search_analyzer,omitempty"`
+	Normalizer     string                 `json:"normalizer,omitempty"`
+	Index          *bool                  `json:"index,omitempty"`
+	DocValues      *bool                  `json:"doc_values,omitempty"`
+	Enabled        *bool                  `json:"enabled,omitempty"`
+	NullValue      interface{}            `json:"null_value,omitempty"`
+	IgnoreAbove    int                    `json:"ignore_above,omitempty"`
+	CopyTo         []string               `json:"copy_to,omitempty"`
+	ScalingFactor  float64                `json:"scaling_factor,omitempty"`
+	Dims           int                    `json:"dims,omitempty"`
+	Similarity     string                 `json:"similarity,omitempty"`
+	Relations      map[string]interface{} `json:"relations,omitempty"`
+	Path           string                 `json:"path,omitempty"`
+	Fields         map[string]*Property   `json:"fields,omitempty"`
+	Properties     map[string]*Property   `json:"properties,omitempty"`
 }
 
 func NewProperty(fieldName, fieldType string) *Property {
@@ -66,3 +208,109 @@ func (p *Property) WithFormat(format string) *Property {
 	p.Format = format
 	return p
 }
+
+// WithProperties sub properties of object or nested field
+func (p *Property) WithProperties(properties ...*Property) *Property {
+	if p.Properties == nil {
+		p.Properties = make(map[string]*Property, len(properties))
+	}
+
+	for _, prop := range properties {
+		p.Properties[prop.n] = prop
+	}
+
+	return p
+}
+
+// WithFields multi-fields, e.g. keyword sub field of text field
+func (p *Property) WithFields(fields ...*Property) *Property {
+	if p.Fields == nil {
+		p.Fields = make(map[string]*Property, len(fields))
+	}
+
+	for _, field := range fields {
+		p.Fields[field.n] = field
+	}
+
+	return p
+}
+
+func (p *Property) WithAnalyzer(analyzer string) *Property {
+	p.Analyzer = analyzer
+	return p
+}
+
+func (p *Property) WithSearchAnalyzer(analyzer string) *Property {
+	p.SearchAnalyzer = analyzer
+	return p
+}
+
+func (p *Property) WithNormalizer(normalizer string) *Property {
+	p.Normalizer = normalizer
+	return p
+}
+
+func (p *Property) WithIndex(index bool) *Property {
+	p.Index = &index
+	return p
+}
+
+func (p *Property) WithDocValues(docValues bool) *Property {
+	p.DocValues = &docValues
+	return p
+}
+
+// WithEnabled enabled:false keeps object field in _source without parsing it
+func (p *Property) WithEnabled(enabled bool) *Property {
+	p.Enabled = &enabled
+	return p
+}
+
+func (p *Property) WithNullValue(value interface{}) *Property {
+	p.NullValue = value
+	return p
+}
+
+func (p *Property) WithIgnoreAbove(length int) *Property {
+	p.IgnoreAbove = length
+	return p
+}
+
+func (p *Property) WithCopyTo(fields ...string) *Property {
+	p.CopyTo = fields
+	return p
+}
+
+// WithScalingFactor scaling factor of scaled_float field
+func (p *Property) WithScalingFactor(factor float64) *Property {
+	p.ScalingFactor = factor
+	return p
+}
+
+// WithDims dims and similarity of dense_vector field, similarity is optional
+func (p *Property) WithDims(dims int, similarity string) *Property {
+	p.Dims = dims
+	p.Similarity = similarity
+	return p
+}
+
+// WithRelations add parent/children relation of join field
+func (p *Property) WithRelations(parent string, children ...string) *Property {
+	if p.Relations == nil {
+		p.Relations = make(map[string]interface{})
+	}
+
+	if len(children) == 1 {
+		p.Relations[parent] = children[0]
+	} else {
+		p.Relations[parent] = children
+	}
+
+	return p
+}
+
+// WithPath target field of alias field
+func (p *Property) WithPath(path string) *Property {
+	p.Path = path
+	return p
+}
@@ -0,0 +1,252 @@
+package elastic
+
+import (
+	"errors"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	tagName = `es`
+)
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+	ipType   = reflect.TypeOf(net.IP{})
+)
+
+// MappingsFromStruct build mappings from fields of struct v, tag example:
+//
+//	es:"name,type=keyword,format=epoch_second"
+//	es:"title,type=text,analyzer=ik_max_word,index=false"
+//
+// field name falls back to json tag and then to go field name, es:"-" skips field,
+// embedded structs are flattened, other embedded fields are named after their type,
+// struct fields become object or nested properties
+func MappingsFromStruct(v interface{}) (*Mappings, error) {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, errors.New("struct is required")
+	}
+
+	properties, err := structProperties(t, map[reflect.Type]bool{})
+	if err != nil {
+		return nil, err
+	}
+
+	mappings := &Mappings{}
+	return mappings.Add(properties...), nil
+}
+
+func structProperties(t reflect.Type, visiting map[reflect.Type]bool) ([]*Property, error) {
+	if visiting[t] {
+		return nil, errors.New("recursive struct " + t.String())
+	}
+
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	properties := make([]*Property, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := parseTag(field)
+		if tag.skip {
+			continue
+		}
+
+		ft := indirectType(field.Type)
+		if field.Anonymous && tag.name == "" && ft.Kind() == reflect.Struct && ft != timeType {
+			embedded, err := structProperties(ft, visiting)
+			if err != nil {
+				return nil, err
+			}
+			properties = append(properties, embedded...)
+			continue
+		}
+
+		if tag.name == "" {
+			// 未展开的内嵌字段同encoding/json使用类型名
+			tag.name = field.Name
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		prop, err := fieldProperty(tag, field.Type, visiting)
+		if err != nil {
+			return nil, errors.New(t.String() + "." + field.Name + ": " + err.Error())
+		}
+
+		if prop != nil {
+			properties = append(properties, prop)
+		}
+	}
+
+	return properties, nil
+}
+
+func fieldProperty(tag fieldTag, ft reflect.Type, visiting map[reflect.Type]bool) (*Property, error) {
+	ft = indirectType(ft)
+	if ft.Kind() == reflect.Slice || ft.Kind() == reflect.Array {
+		if ft != ipType && ft.Elem().Kind() != reflect.Uint8 {
+			ft = indirectType(ft.Elem())
+		}
+	}
+
+	fieldType := tag.fieldType
+	if fieldType == "" {
+		fieldType = inferType(ft)
+	}
+
+	if fieldType == "" {
+		if ft.Kind() == reflect.Interface {
+			// 交给动态映射
+			return nil, nil
+		}
+		return nil, errors.New("can not infer type of " + ft.String())
+	}
+
+	prop := NewProperty(tag.name, fieldType).
+		WithFormat(tag.format).
+		WithAnalyzer(tag.analyzer).
+		WithSearchAnalyzer(tag.searchAnalyzer).
+		WithNormalizer(tag.normalizer).
+		WithIgnoreAbove(tag.ignoreAbove)
+
+	prop.Index, prop.DocValues = tag.index, tag.docValues
+
+	if (fieldType == TypeObject || fieldType == TypeNested) && ft.Kind() == reflect.Struct {
+		properties, err := structProperties(ft, visiting)
+		if err != nil {
+			return nil, err
+		}
+		prop.WithProperties(properties...)
+	}
+
+	return prop, nil
+}
+
+func inferType(ft reflect.Type) string {
+	switch ft {
+	case timeType:
+		return TypeDate
+	case ipType:
+		return TypeIp
+	}
+
+	switch ft.Kind() {
+	case reflect.Bool:
+		return TypeBoolean
+	case reflect.Int8:
+		return TypeByte
+	case reflect.Int16, reflect.Uint8:
+		return TypeShort
+	case reflect.Int32, reflect.Uint16:
+		return TypeInteger
+	case reflect.Int, reflect.Int64, reflect.Uint32:
+		return TypeLong
+	case reflect.Uint, reflect.Uint64:
+		return TypeUlong
+	case reflect.Float32:
+		return TypeFloat
+	case reflect.Float64:
+		return TypeDouble
+	case reflect.String:
+		return TypeKeyword
+	case reflect.Slice, reflect.Array:
+		if ft.Elem().Kind() == reflect.Uint8 {
+			return TypeBinary
+		}
+	case reflect.Struct, reflect.Map:
+		return TypeObject
+	}
+
+	return ""
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+type fieldTag struct {
+	skip           bool
+	name           string
+	fieldType      string
+	format         string
+	analyzer       string
+	searchAnalyzer string
+	normalizer     string
+	index          *bool
+	docValues      *bool
+	ignoreAbove    int
+}
+
+func parseTag(field reflect.StructField) (tag fieldTag) {
+	value, ok := field.Tag.Lookup(tagName)
+	if value == "-" {
+		tag.skip = true
+		return
+	}
+
+	items := strings.Split(value, ",")
+	tag.name = items[0]
+
+	for _, item := range items[1:] {
+		kv := strings.SplitN(item, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+
+		val := strings.TrimSpace(kv[1])
+		switch strings.TrimSpace(kv[0]) {
+		case "type":
+			tag.fieldType = val
+		case "format":
+			tag.format = val
+		case "analyzer":
+			tag.analyzer = val
+		case "search_analyzer":
+			tag.searchAnalyzer = val
+		case "normalizer":
+			tag.normalizer = val
+		case "index":
+			index := val != "false"
+			tag.index = &index
+		case "doc_values":
+			docValues := val != "false"
+			tag.docValues = &docValues
+		case "ignore_above":
+			tag.ignoreAbove, _ = strconv.Atoi(val)
+		}
+	}
+
+	if tag.name == "" {
+		jsonName := strings.Split(field.Tag.Get("json"), ",")[0]
+		switch {
+		case jsonName == "-":
+			// 没有es标签时跟随json忽略
+			tag.skip = !ok
+		case jsonName != "":
+			tag.name = jsonName
+		case !field.Anonymous:
+			tag.name = field.Name
+		}
+	}
+
+	if tag.name == "" && !field.Anonymous {
+		tag.name = field.Name
+	}
+
+	return
+}
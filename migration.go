@@ -0,0 +1,403 @@
+package elastic
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grpc-boot/base"
+	"github.com/grpc-boot/base/core/zaplogger"
+)
+
+const (
+	migrationLockId     = `lock`
+	migrationTypeRecord = `migration`
+	migrationTypeLock   = `lock`
+	defaultLockTtl      = time.Minute * 30
+)
+
+var (
+	ErrMigrationLocked   = errors.New("migration is locked by another runner")
+	ErrMigrationChecksum = errors.New("checksum of applied migration changed")
+)
+
+// MigrationStep one action of a migration, Describe is used to build checksum of migration
+type MigrationStep interface {
+	Describe() string
+	Apply(timeout time.Duration, conn *Connection) error
+}
+
+// Migration steps are applied in order, Version must be unique and increasing
+type Migration struct {
+	Version int64
+	Name    string
+	Steps   []MigrationStep
+}
+
+func (m *Migration) Checksum() string {
+	var buf strings.Builder
+
+	buf.WriteString(strconv.FormatInt(m.Version, 10))
+	buf.WriteByte('|')
+	buf.WriteString(m.Name)
+
+	for _, step := range m.Steps {
+		buf.WriteByte('|')
+		buf.WriteString(step.Describe())
+	}
+
+	sum := md5.Sum([]byte(buf.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+// MigrationRecord applied migration stored in state index
+type MigrationRecord struct {
+	Version   int64  `json:"version"`
+	Name      string `json:"name"`
+	Checksum  string `json:"checksum"`
+	AppliedAt int64  `json:"appliedAt"`
+}
+
+// Migrator apply pending migrations and record them in state index,
+// a lock document in state index keeps concurrent runners from racing
+type Migrator struct {
+	conn       *Connection
+	stateIndex string
+	owner      string
+	lockTtl    time.Duration
+	migrations []*Migration
+}
+
+func NewMigrator(conn *Connection, stateIndex string) *Migrator {
+	hostname, _ := os.Hostname()
+
+	return &Migrator{
+		conn:       conn,
+		stateIndex: stateIndex,
+		owner:      hostname + ":" + strconv.Itoa(os.Getpid()),
+		lockTtl:    defaultLockTtl,
+	}
+}
+
+// WithLockTtl lock held longer than ttl is treated as stale and taken over
+func (m *Migrator) WithLockTtl(ttl time.Duration) *Migrator {
+	m.lockTtl = ttl
+	return m
+}
+
+func (m *Migrator) Register(migrations ...*Migration) *Migrator {
+	m.migrations = append(m.migrations, migrations...)
+	return m
+}
+
+// Pending migrations not yet applied, error when checksum of applied migration changed
+func (m *Migrator) Pending(timeout time.Duration) ([]*Migration, error) {
+	if err := m.ensureStateIndex(timeout); err != nil {
+		return nil, err
+	}
+
+	return m.pending(timeout)
+}
+
+// Run apply pending migrations in order, return migrations applied by this run.
+// The lock is renewed before every step and periodically while a step runs,
+// Run aborts with ErrMigrationLocked when the lock was taken over by another runner
+func (m *Migrator) Run(timeout time.Duration) (applied []*Migration, err error) {
+	if err = m.ensureStateIndex(timeout); err != nil {
+		return nil, err
+	}
+
+	held, err := m.lock(timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		m.heartbeat(ctx, cancel, timeout, held)
+	}()
+
+	defer func() {
+		cancel()
+		<-stopped
+		m.unlock(timeout, held)
+	}()
+
+	pending, err := m.pending(timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, migration := range pending {
+		for index, step := range migration.Steps {
+			if err = m.renew(timeout, held); err != nil {
+				return applied, err
+			}
+
+			if cs, ok := step.(contextStep); ok {
+				err = cs.applyContext(ctx, timeout, m.conn)
+			} else {
+				err = step.Apply(timeout, m.conn)
+			}
+
+			if lost := held.lostErr(); lost != nil {
+				return applied, lost
+			}
+
+			if err != nil {
+				return applied, fmt.Errorf("migration %d step %d: %w", migration.Version, index, err)
+			}
+		}
+
+		if err = m.record(timeout, migration); err != nil {
+			return applied, err
+		}
+
+		applied = append(applied, migration)
+	}
+
+	return applied, nil
+}
+
+// Applied migration records of state index ordered by version
+func (m *Migrator) Applied(timeout time.Duration) ([]MigrationRecord, error) {
+	query := &Query{}
+	query.From(m.stateIndex).
+		Where(AndCondition(Term("type", migrationTypeRecord))).
+		Limit(10000).
+		OrderBy(Asc("version"))
+
+	rs, err := SearchAs[MigrationRecord](timeout, m.conn, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return rs.Sources(), nil
+}
+
+// record 等待刷新后返回，保证下一次运行能搜索到
+func (m *Migrator) record(timeout time.Duration, migration *Migration) error {
+	row := base.JsonParam{
+		"_id":       "v" + strconv.FormatInt(migration.Version, 10),
+		"type":      migrationTypeRecord,
+		"version":   migration.Version,
+		"name":      migration.Name,
+		"checksum":  migration.Checksum(),
+		"appliedAt": time.Now().Unix(),
+	}
+
+	_, err := m.conn.DocsInsert(timeout, m.stateIndex, row, NewDocOptions().Refresh(RefreshWaitFor))
+	return err
+}
+
+func (m *Migrator) pending(timeout time.Duration) ([]*Migration, error) {
+	migrations := make([]*Migration, len(m.migrations))
+	copy(migrations, m.migrations)
+	sort.SliceStable(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	for i := 1; i < len(migrations); i++ {
+		if migrations[i].Version == migrations[i-1].Version {
+			return nil, fmt.Errorf("duplicate migration version %d", migrations[i].Version)
+		}
+	}
+
+	records, err := m.Applied(timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int64]string, len(records))
+	for _, record := range records {
+		applied[record.Version] = record.Checksum
+	}
+
+	pending := make([]*Migration, 0, len(migrations))
+	for _, migration := range migrations {
+		checksum, ok := applied[migration.Version]
+		if !ok {
+			pending = append(pending, migration)
+			continue
+		}
+
+		if checksum != migration.Checksum() {
+			return nil, fmt.Errorf("%w version:%d", ErrMigrationChecksum, migration.Version)
+		}
+	}
+
+	return pending, nil
+}
+
+func (m *Migrator) ensureStateIndex(timeout time.Duration) error {
+	exists, err := m.conn.IndexExists(timeout, m.stateIndex)
+	if err != nil || exists {
+		return err
+	}
+
+	mappings := &Mappings{}
+	mappings.Add(
+		NewProperty("type", TypeKeyword),
+		NewProperty("version", TypeLong),
+		NewProperty("name", TypeKeyword),
+		NewProperty("checksum", TypeKeyword),
+		NewProperty("appliedAt", TypeDate).WithFormat(FormatUnixTime),
+		NewProperty("owner", TypeKeyword),
+		NewProperty("expireAt", TypeLong),
+	)
+
+	_, err = m.conn.IndexCreate(timeout, m.stateIndex, &Settings{NumberOfShards: 1}, mappings)
+	if err != nil && strings.Contains(err.Error(), "resource_already_exists_exception") {
+		return nil
+	}
+
+	return err
+}
+
+// migrationLock 当前持有的锁文档版本，续期后更新
+type migrationLock struct {
+	mutex       sync.Mutex
+	seqNo       int64
+	primaryTerm int64
+	lost        error
+}
+
+func (ml *migrationLock) lostErr() error {
+	ml.mutex.Lock()
+	defer ml.mutex.Unlock()
+
+	return ml.lost
+}
+
+// contextStep 长时间运行的步骤，锁丢失时ctx被取消
+type contextStep interface {
+	applyContext(ctx context.Context, timeout time.Duration, conn *Connection) error
+}
+
+func (m *Migrator) lockDoc() base.JsonParam {
+	return base.JsonParam{
+		"type":     migrationTypeLock,
+		"owner":    m.owner,
+		"expireAt": time.Now().Add(m.lockTtl).Unix(),
+	}
+}
+
+// lock 通过_create保证只有一个执行者，过期的锁按seq_no删除后重新获取
+func (m *Migrator) lock(timeout time.Duration) (*migrationLock, error) {
+	for i := 0; i < 2; i++ {
+		resp, err := m.conn.Post(timeout, "/"+m.stateIndex+"/_create/"+migrationLockId, base.Bytes2String(m.lockDoc().JsonMarshal()))
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.IsOk() {
+			res, err := resp.UnmarshalIndexResult()
+			if err != nil {
+				return nil, err
+			}
+			return &migrationLock{seqNo: res.SeqNo, primaryTerm: res.PrimaryTerm}, nil
+		}
+
+		if !resp.Is(http.StatusConflict) {
+			return nil, resp.Error()
+		}
+
+		current, err := m.conn.DocsGet(timeout, m.stateIndex, migrationLockId)
+		if err != nil {
+			return nil, err
+		}
+
+		if current.Found && current.Source.Int64("expireAt") > time.Now().Unix() {
+			return nil, fmt.Errorf("%w owner:%s", ErrMigrationLocked, current.Source.String("owner"))
+		}
+
+		if current.Found {
+			_, err = m.conn.DocsDelete(timeout, m.stateIndex, migrationLockId, NewDocOptions().IfSeqNo(current.SeqNo, current.PrimaryTerm))
+			if err != nil && !errors.Is(err, ErrVersionConflict) {
+				return nil, err
+			}
+		}
+	}
+
+	return nil, ErrMigrationLocked
+}
+
+// renew 按seq_no延长锁的过期时间，冲突说明锁已被其他执行者接管
+func (m *Migrator) renew(timeout time.Duration, held *migrationLock) error {
+	held.mutex.Lock()
+	defer held.mutex.Unlock()
+
+	if held.lost != nil {
+		return held.lost
+	}
+
+	row := m.lockDoc()
+	row["_id"] = migrationLockId
+
+	res, err := m.conn.DocsInsert(timeout, m.stateIndex, row, NewDocOptions().IfSeqNo(held.seqNo, held.primaryTerm))
+	if errors.Is(err, ErrVersionConflict) {
+		held.lost = fmt.Errorf("%w: lock was taken over", ErrMigrationLocked)
+		return held.lost
+	}
+
+	if err != nil {
+		return err
+	}
+
+	held.seqNo, held.primaryTerm = res.SeqNo, res.PrimaryTerm
+	return nil
+}
+
+// heartbeat 步骤执行期间定期续期，锁丢失时取消ctx，续期请求失败时下次重试
+func (m *Migrator) heartbeat(ctx context.Context, cancel context.CancelFunc, timeout time.Duration, held *migrationLock) {
+	ticker := time.NewTicker(m.lockTtl / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		err := m.renew(timeout, held)
+		if held.lostErr() != nil {
+			cancel()
+			return
+		}
+
+		if err != nil {
+			base.Warn("renew migration lock failed",
+				zaplogger.Error(err),
+			)
+		}
+	}
+}
+
+// unlock 只删除自己持有的锁，锁过期后被其他执行者接管时删除会冲突
+func (m *Migrator) unlock(timeout time.Duration, held *migrationLock) {
+	held.mutex.Lock()
+	defer held.mutex.Unlock()
+
+	if held.lost != nil {
+		return
+	}
+
+	_, err := m.conn.DocsDelete(timeout, m.stateIndex, migrationLockId, NewDocOptions().IfSeqNo(held.seqNo, held.primaryTerm))
+	if err != nil && !errors.Is(err, ErrVersionConflict) {
+		base.Warn("release migration lock failed",
+			zaplogger.Error(err),
+		)
+	}
+}
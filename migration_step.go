@@ -0,0 +1,159 @@
+package elastic
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/grpc-boot/base"
+	jsoniter "github.com/json-iterator/go"
+)
+
+var canonicalConfig = jsoniter.Config{SortMapKeys: true}.Froze()
+
+// canonicalJson 默认配置不排序map的key，描述需要稳定才能得到相同的checksum
+func canonicalJson(data []byte) string {
+	var value interface{}
+	if err := canonicalConfig.Unmarshal(data, &value); err != nil {
+		return base.Bytes2String(data)
+	}
+
+	sorted, err := canonicalConfig.Marshal(value)
+	if err != nil {
+		return base.Bytes2String(data)
+	}
+
+	return base.Bytes2String(sorted)
+}
+
+type createIndexStep struct {
+	index    string
+	settings *Settings
+	mappings *Mappings
+}
+
+// CreateIndexStep create index with settings and mappings
+func CreateIndexStep(index string, settings *Settings, mappings *Mappings) MigrationStep {
+	return &createIndexStep{index: index, settings: settings, mappings: mappings}
+}
+
+func (cis *createIndexStep) Describe() string {
+	desc := "create " + cis.index + " " + canonicalJson(cis.settings.Marshal())
+	if cis.mappings != nil {
+		desc += " " + canonicalJson(cis.mappings.Marshal())
+	}
+	return desc
+}
+
+func (cis *createIndexStep) Apply(timeout time.Duration, conn *Connection) error {
+	_, err := conn.IndexCreate(timeout, cis.index, cis.settings, cis.mappings)
+	return err
+}
+
+type addFieldsStep struct {
+	index    string
+	mappings *Mappings
+}
+
+// AddFieldsStep add fields to index through MappingsAlter
+func AddFieldsStep(index string, mappings *Mappings) MigrationStep {
+	return &addFieldsStep{index: index, mappings: mappings}
+}
+
+func (afs *addFieldsStep) Describe() string {
+	return "mappings " + afs.index + " " + canonicalJson(afs.mappings.Marshal())
+}
+
+func (afs *addFieldsStep) Apply(timeout time.Duration, conn *Connection) error {
+	_, err := conn.MappingsAlter(timeout, afs.index, afs.mappings)
+	return err
+}
+
+type alterSettingsStep struct {
+	index    string
+	settings base.JsonParam
+}
+
+// AlterSettingsStep update dynamic settings through SettingsAlter
+func AlterSettingsStep(index string, settings base.JsonParam) MigrationStep {
+	return &alterSettingsStep{index: index, settings: settings}
+}
+
+func (ass *alterSettingsStep) Describe() string {
+	return "settings " + ass.index + " " + canonicalJson(ass.settings.JsonMarshal())
+}
+
+func (ass *alterSettingsStep) Apply(timeout time.Duration, conn *Connection) error {
+	_, err := conn.SettingsAlter(timeout, ass.index, ass.settings)
+	return err
+}
+
+type reindexStep struct {
+	source string
+	dest   string
+	script string
+}
+
+// ReindexStep copy documents from source to dest, script is an optional painless transform
+func ReindexStep(source, dest string, script string) MigrationStep {
+	return &reindexStep{source: source, dest: dest, script: script}
+}
+
+func (rs *reindexStep) Describe() string {
+	return "reindex " + rs.source + " " + rs.dest + " " + rs.script
+}
+
+func (rs *reindexStep) Apply(timeout time.Duration, conn *Connection) error {
+	return rs.applyContext(context.Background(), timeout, conn)
+}
+
+// applyContext 迁移锁丢失时取消复制任务
+func (rs *reindexStep) applyContext(ctx context.Context, timeout time.Duration, conn *Connection) error {
+	_, err := conn.serverCopy(ctx, timeout, rs.source, rs.dest, rs.script)
+	return err
+}
+
+type swapAliasStep struct {
+	alias string
+	from  string
+	to    string
+}
+
+// SwapAliasStep atomically move alias from index to another, from can be empty
+func SwapAliasStep(alias, from, to string) MigrationStep {
+	return &swapAliasStep{alias: alias, from: from, to: to}
+}
+
+func (sas *swapAliasStep) Describe() string {
+	return "alias " + sas.alias + " " + sas.from + " " + sas.to
+}
+
+func (sas *swapAliasStep) Apply(timeout time.Duration, conn *Connection) error {
+	actions := make([]AliasAction, 0, 2)
+	if sas.from != "" {
+		actions = append(actions, RemoveAlias(sas.from, sas.alias))
+	}
+	actions = append(actions, AddAlias(sas.to, NewAlias(sas.alias)))
+
+	_, err := conn.AliasesUpdate(timeout, actions...)
+	return err
+}
+
+type funcStep struct {
+	name    string
+	version int
+	fn      func(timeout time.Duration, conn *Connection) error
+}
+
+// FuncStep custom step, change version when behavior of fn changes so that checksum changes
+func FuncStep(name string, version int, fn func(timeout time.Duration, conn *Connection) error) MigrationStep {
+	return &funcStep{name: name, version: version, fn: fn}
+}
+
+func (fs *funcStep) Describe() string {
+	return "func " + fs.name + " " + strconv.Itoa(fs.version)
+}
+
+func (fs *funcStep) Apply(timeout time.Duration, conn *Connection) error {
+	return fs.fn(timeout, conn)
+}
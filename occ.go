@@ -0,0 +1,89 @@
+package elastic
+
+import (
+	"errors"
+	"time"
+
+	"github.com/grpc-boot/elastic/results"
+
+	"github.com/grpc-boot/base"
+)
+
+const (
+	defaultModifyRetries = 3
+)
+
+var (
+	ErrVersionConflict  = errors.New("version conflict")
+	ErrDocumentNotFound = errors.New("document not found")
+)
+
+// ConflictError returned when server responds 409,
+// errors.Is(err, ErrVersionConflict) reports true for it
+type ConflictError struct {
+	Body []byte
+}
+
+func (ce *ConflictError) Error() string {
+	return `status:409 error msg:` + string(ce.Body)
+}
+
+func (ce *ConflictError) Is(target error) bool {
+	return target == ErrVersionConflict
+}
+
+// ModifyFunc change document read by DocsModify, the returned document replaces the stored one
+type ModifyFunc func(doc base.JsonParam) (base.JsonParam, error)
+
+// DocsModify read document, apply modify and write it back only when it is unchanged since read,
+// read and modify again on conflict, retries less than 1 means 3.
+// opts apply to write, only routing of opts applies to read as the whole source is needed
+func (c *Connection) DocsModify(timeout time.Duration, index string, id string, retries int, modify ModifyFunc, opts ...*DocOptions) (*results.IndexResult, error) {
+	if retries < 1 {
+		retries = defaultModifyRetries
+	}
+
+	var (
+		conflict error
+		read     = pick(opts, "routing")
+	)
+
+	for i := 0; i <= retries; i++ {
+		current, err := c.DocsGet(timeout, index, id, read)
+		if err != nil {
+			return nil, err
+		}
+
+		if !current.Found {
+			return nil, ErrDocumentNotFound
+		}
+
+		doc, err := modify(current.Source)
+		if err != nil {
+			return nil, err
+		}
+
+		write := append(opts[:len(opts):len(opts)], NewDocOptions().IfSeqNo(current.SeqNo, current.PrimaryTerm))
+		ir, err := c.docsPut(timeout, index, id, doc, write...)
+		if !errors.Is(err, ErrVersionConflict) {
+			return ir, err
+		}
+		conflict = err
+	}
+
+	return nil, conflict
+}
+
+// docsPut 整体替换文档
+func (c *Connection) docsPut(timeout time.Duration, index string, id string, doc base.JsonParam, opts ...*DocOptions) (*results.IndexResult, error) {
+	resp, err := c.Put(timeout, withPath("/"+index+"/_doc/"+id, opts), base.Bytes2String(doc.JsonMarshal()))
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.IsOk() {
+		return resp.UnmarshalIndexResult()
+	}
+
+	return nil, resp.Error()
+}
@@ -3,12 +3,17 @@ package elastic
 var (
 	defaultOption = func() *Option {
 		return &Option{
-			DialTimeoutSecond:     1,
-			KeepaliveSecond:       60,
-			IdleConnTimeoutSecond: 30,
-			MaxIdleConns:          8,
-			MaxIdleConnsPerHost:   4,
-			MaxConnsPerHost:       16,
+			DialTimeoutSecond:       1,
+			KeepaliveSecond:         60,
+			IdleConnTimeoutSecond:   30,
+			MaxIdleConns:            8,
+			MaxIdleConnsPerHost:     4,
+			MaxConnsPerHost:         16,
+			SlowBodyMaxLength:       defaultSlowBodyMaxLength,
+			BreakerMinRequests:      20,
+			BreakerWindowSecond:     10,
+			BreakerOpenSecond:       5,
+			BreakerHalfOpenRequests: 1,
 		}
 	}
 )
@@ -23,6 +28,29 @@ type Option struct {
 	MaxIdleConns          int    `json:"maxIdleConns" yaml:"maxIdleConns"`
 	MaxIdleConnsPerHost   int    `json:"maxIdleConnsPerHost" yaml:"maxIdleConnsPerHost"`
 	MaxConnsPerHost       int    `json:"maxConnsPerHost" yaml:"maxConnsPerHost"`
+
+	// SlowThresholdMillisecond requests slower than it are reported, 0 disable
+	SlowThresholdMillisecond int64 `json:"slowThresholdMillisecond" yaml:"slowThresholdMillisecond"`
+	// SlowBodyMaxLength request body is truncated to it in slow report, negative means redacted
+	SlowBodyMaxLength int         `json:"slowBodyMaxLength" yaml:"slowBodyMaxLength"`
+	SlowHandler       SlowHandler `json:"-" yaml:"-"`
+
+	// BreakerErrorRate circuit breaker opens when failure rate of window reaches it, 0 disable
+	BreakerErrorRate float64 `json:"breakerErrorRate" yaml:"breakerErrorRate"`
+	// BreakerSlowMillisecond requests slower than it are counted as failure, 0 disable
+	BreakerSlowMillisecond  int64               `json:"breakerSlowMillisecond" yaml:"breakerSlowMillisecond"`
+	BreakerMinRequests      int                 `json:"breakerMinRequests" yaml:"breakerMinRequests"`
+	BreakerWindowSecond     int64               `json:"breakerWindowSecond" yaml:"breakerWindowSecond"`
+	BreakerOpenSecond       int64               `json:"breakerOpenSecond" yaml:"breakerOpenSecond"`
+	BreakerHalfOpenRequests int                 `json:"breakerHalfOpenRequests" yaml:"breakerHalfOpenRequests"`
+	BreakerStateHandler     BreakerStateHandler `json:"-" yaml:"-"`
+
+	SearchLimit LimitOption `json:"searchLimit" yaml:"searchLimit"`
+	BulkLimit   LimitOption `json:"bulkLimit" yaml:"bulkLimit"`
+	AdminLimit  LimitOption `json:"adminLimit" yaml:"adminLimit"`
+
+	// MaxResponseBytes response body larger than it is rejected with ErrResponseTooLarge, 0 unlimited
+	MaxResponseBytes int64 `json:"maxResponseBytes" yaml:"maxResponseBytes"`
 }
 
 func loadOption(option Option) *Option {
@@ -55,5 +83,49 @@ func loadOption(option Option) *Option {
 		opt.MaxConnsPerHost = option.MaxConnsPerHost
 	}
 
+	if option.SlowThresholdMillisecond > 0 {
+		opt.SlowThresholdMillisecond = option.SlowThresholdMillisecond
+	}
+
+	if option.SlowBodyMaxLength != 0 {
+		opt.SlowBodyMaxLength = option.SlowBodyMaxLength
+	}
+
+	opt.SlowHandler = option.SlowHandler
+
+	if option.BreakerErrorRate > 0 {
+		opt.BreakerErrorRate = option.BreakerErrorRate
+	}
+
+	if option.BreakerSlowMillisecond > 0 {
+		opt.BreakerSlowMillisecond = option.BreakerSlowMillisecond
+	}
+
+	if option.BreakerMinRequests > 0 {
+		opt.BreakerMinRequests = option.BreakerMinRequests
+	}
+
+	if option.BreakerWindowSecond > 0 {
+		opt.BreakerWindowSecond = option.BreakerWindowSecond
+	}
+
+	if option.BreakerOpenSecond > 0 {
+		opt.BreakerOpenSecond = option.BreakerOpenSecond
+	}
+
+	if option.BreakerHalfOpenRequests > 0 {
+		opt.BreakerHalfOpenRequests = option.BreakerHalfOpenRequests
+	}
+
+	opt.BreakerStateHandler = option.BreakerStateHandler
+
+	opt.SearchLimit = option.SearchLimit
+	opt.BulkLimit = option.BulkLimit
+	opt.AdminLimit = option.AdminLimit
+
+	if option.MaxResponseBytes > 0 {
+		opt.MaxResponseBytes = option.MaxResponseBytes
+	}
+
 	return opt
 }
@@ -1,6 +1,7 @@
 package elastic
 
 import (
+	"net/http"
 	"strconv"
 	"strings"
 	"time"
@@ -26,6 +27,7 @@ type Query struct {
 	where  string
 	prefix string
 	order  string
+	seqNo  bool
 }
 
 func (q *Query) Select(fields ...string) *Query {
@@ -33,6 +35,12 @@ func (q *Query) Select(fields ...string) *Query {
 	return q
 }
 
+// WithSeqNo return _version, _seq_no and _primary_term of hits, which can be passed to DocOptions.IfSeqNo
+func (q *Query) WithSeqNo() *Query {
+	q.seqNo = true
+	return q
+}
+
 func (q *Query) From(index string) *Query {
 	q.index = index
 	return q
@@ -94,6 +102,10 @@ func (q *Query) Build() string {
 		n += 16
 	}
 
+	if q.seqNo {
+		n += 42
+	}
+
 	buf.Grow(n)
 	buf.WriteByte('{')
 	if len(q.fields) > 0 {
@@ -115,6 +127,10 @@ func (q *Query) Build() string {
 	buf.WriteString(`,"size":`)
 	buf.WriteString(sizeStr)
 
+	if q.seqNo {
+		buf.WriteString(`,"version":true,"seq_no_primary_term":true`)
+	}
+
 	buf.WriteString(`,"sort":[`)
 	buf.WriteString(q.order)
 	buf.WriteString(`]}`)
@@ -122,15 +138,43 @@ func (q *Query) Build() string {
 	return buf.String()
 }
 
+// buildQuery only query part, used by apis which reject from, size and sort
+func (q *Query) buildQuery() string {
+	query := q.queryString()
+
+	var buf strings.Builder
+	buf.Grow(9 + len(query) + 1)
+	buf.WriteString(`{"query":`)
+	buf.WriteString(query)
+	buf.WriteByte('}')
+
+	return buf.String()
+}
+
+func (q *Query) queryString() string {
+	where := q.where
+	if where == "" {
+		where = "*"
+	}
+
+	var buf strings.Builder
+	buf.Grow(27 + len(where) + 3)
+	buf.WriteString(`{"query_string":{"query":"`)
+	buf.WriteString(where)
+	buf.WriteString(`"}}`)
+
+	return buf.String()
+}
+
 func (q *Query) Search(timeout time.Duration, conn *Connection) (result *results.SearchResult, err error) {
-	param := q.Build()
-	resp, err := conn.Get(timeout, "/"+q.index+"/_search", param)
+	result = &results.SearchResult{}
+	resp, err := conn.decode(timeout, http.MethodGet, "/"+q.index+"/_search", q.Build(), result)
 	if err != nil {
 		return nil, err
 	}
 
 	if resp.IsOk() {
-		return resp.UnmarshalSearchResult()
+		return result, nil
 	}
 
 	return nil, resp.Error()
@@ -144,3 +188,19 @@ func (q *Query) SearchRows(timeout time.Duration, conn *Connection) (result *res
 
 	return rs.ToRows(), nil
 }
+
+// Count
+// link: https://www.elastic.co/guide/en/elasticsearch/reference/current/search-count.html
+func (q *Query) Count(timeout time.Duration, conn *Connection) (count int64, err error) {
+	result := &results.CountResult{}
+	resp, err := conn.decode(timeout, http.MethodGet, "/"+q.index+"/_count", q.buildQuery(), result)
+	if err != nil {
+		return 0, err
+	}
+
+	if resp.IsOk() {
+		return result.Count, nil
+	}
+
+	return 0, resp.Error()
+}
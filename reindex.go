@@ -0,0 +1,140 @@
+package elastic
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/grpc-boot/elastic/results"
+
+	"github.com/grpc-boot/base"
+	jsoniter "github.com/json-iterator/go"
+)
+
+const (
+	OpTypeIndex  = `index`
+	OpTypeCreate = `create`
+)
+
+// ReindexRemote source cluster of reindex, host must be listed in reindex.remote.whitelist of destination cluster
+// link: https://www.elastic.co/guide/en/elasticsearch/reference/current/docs-reindex.html#reindex-from-remote
+type ReindexRemote struct {
+	Host           string            `json:"host"`
+	Username       string            `json:"username,omitempty"`
+	Password       string            `json:"password,omitempty"`
+	Headers        map[string]string `json:"headers,omitempty"`
+	SocketTimeout  string            `json:"socket_timeout,omitempty"`
+	ConnectTimeout string            `json:"connect_timeout,omitempty"`
+}
+
+type ReindexDest struct {
+	Index       string `json:"index"`
+	OpType      string `json:"op_type,omitempty"`
+	Pipeline    string `json:"pipeline,omitempty"`
+	VersionType string `json:"version_type,omitempty"`
+}
+
+// Reindex body of server side reindex, source documents are selected by index, where condition and fields of Query
+// link: https://www.elastic.co/guide/en/elasticsearch/reference/current/docs-reindex.html
+type Reindex struct {
+	source    *Query
+	remote    *ReindexRemote
+	dest      ReindexDest
+	script    *Script
+	conflicts string
+	batchSize int
+}
+
+// NewReindex Limit of source is sent as max_docs
+func NewReindex(source *Query, destIndex string) *Reindex {
+	return &Reindex{source: source, dest: ReindexDest{Index: destIndex}}
+}
+
+// WithOpType OpTypeCreate only copies documents missing in destination
+func (r *Reindex) WithOpType(opType string) *Reindex {
+	r.dest.OpType = opType
+	return r
+}
+
+func (r *Reindex) WithPipeline(pipeline string) *Reindex {
+	r.dest.Pipeline = pipeline
+	return r
+}
+
+func (r *Reindex) WithVersionType(versionType string) *Reindex {
+	r.dest.VersionType = versionType
+	return r
+}
+
+// WithScript painless transform of ctx._source, setting ctx.op to noop drops document
+func (r *Reindex) WithScript(script *Script) *Reindex {
+	r.script = script
+	return r
+}
+
+func (r *Reindex) WithRemote(remote *ReindexRemote) *Reindex {
+	r.remote = remote
+	return r
+}
+
+// WithConflicts ConflictsProceed counts version conflicts instead of aborting
+func (r *Reindex) WithConflicts(conflicts string) *Reindex {
+	r.conflicts = conflicts
+	return r
+}
+
+// WithBatchSize documents of one scroll batch, default 1000
+func (r *Reindex) WithBatchSize(size int) *Reindex {
+	r.batchSize = size
+	return r
+}
+
+func (r *Reindex) Marshal() []byte {
+	type source struct {
+		Index  string              `json:"index"`
+		Query  jsoniter.RawMessage `json:"query,omitempty"`
+		Fields jsoniter.RawMessage `json:"_source,omitempty"`
+		Size   int                 `json:"size,omitempty"`
+		Remote *ReindexRemote      `json:"remote,omitempty"`
+	}
+
+	body := struct {
+		Conflicts string      `json:"conflicts,omitempty"`
+		MaxDocs   int         `json:"max_docs,omitempty"`
+		Source    source      `json:"source"`
+		Dest      ReindexDest `json:"dest"`
+		Script    *Script     `json:"script,omitempty"`
+	}{
+		Conflicts: r.conflicts,
+		MaxDocs:   r.source.size,
+		Source:    source{Index: r.source.index, Size: r.batchSize, Remote: r.remote},
+		Dest:      r.dest,
+		Script:    r.script,
+	}
+
+	if r.source.where != "" {
+		body.Source.Query = jsoniter.RawMessage(r.source.queryString())
+	}
+
+	if r.source.fields != "" {
+		body.Source.Fields = jsoniter.RawMessage("[" + r.source.fields + "]")
+	}
+
+	data, _ := base.JsonMarshal(body)
+	return data
+}
+
+// Reindex copy documents on server, with Async option only Task of result is set
+// link: https://www.elastic.co/guide/en/elasticsearch/reference/current/docs-reindex.html
+func (c *Connection) Reindex(timeout time.Duration, reindex *Reindex, opts ...*DocOptions) (*results.BulkByScrollResult, error) {
+	result := &results.BulkByScrollResult{}
+	resp, err := c.decode(timeout, http.MethodPost, withPath("/_reindex", opts), base.Bytes2String(reindex.Marshal()), result)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.IsOk() {
+		return result, nil
+	}
+
+	return nil, resp.Error()
+}
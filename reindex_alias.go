@@ -0,0 +1,261 @@
+package elastic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/grpc-boot/elastic/results"
+
+	"github.com/grpc-boot/base"
+)
+
+const (
+	defaultReindexBatchSize = 1000
+	scrollKeepAlive         = `5m`
+)
+
+var (
+	ErrReindexCountMismatch = errors.New("document count of target index mismatch")
+
+	// errCopyRunning 取消复制任务失败，任务可能仍在写入目标索引
+	errCopyRunning = errors.New("copy task may be still running")
+)
+
+// TransformFunc client side transform of document, return false to drop document
+type TransformFunc func(id string, doc base.JsonParam) (base.JsonParam, bool)
+
+// ReindexPlan move ReadAlias and WriteAlias from SourceIndex to a new TargetIndex.
+// Documents written to source index while copying are not copied, pause writers during reindex.
+type ReindexPlan struct {
+	ReadAlias  string
+	WriteAlias string
+	// SourceIndex resolved from ReadAlias when empty
+	SourceIndex string
+	// TargetIndex defaults to ReadAlias with a time version suffix
+	TargetIndex string
+	Settings    *Settings
+	Mappings    *Mappings
+	// Script painless script of server side _reindex
+	Script string
+	// Transform copy documents with client side scroll and bulk instead of server side _reindex
+	Transform    TransformFunc
+	BatchSize    int
+	DeleteSource bool
+	// CopyTimeout max wait of server side copy which runs as a task, 0 means no limit
+	CopyTimeout time.Duration
+}
+
+// ReindexReport result of ReindexWithAlias
+type ReindexReport struct {
+	SourceIndex string `json:"sourceIndex"`
+	TargetIndex string `json:"targetIndex"`
+	SourceCount int64  `json:"sourceCount"`
+	TargetCount int64  `json:"targetCount"`
+	Dropped     int64  `json:"dropped"`
+}
+
+// ReindexWithAlias create target index, copy documents, check counts and then atomically move aliases
+func (c *Connection) ReindexWithAlias(timeout time.Duration, plan *ReindexPlan) (*ReindexReport, error) {
+	if plan.ReadAlias == "" {
+		return nil, errors.New("read alias is required")
+	}
+
+	report := &ReindexReport{SourceIndex: plan.SourceIndex, TargetIndex: plan.TargetIndex}
+
+	if report.SourceIndex == "" {
+		indices, err := c.AliasIndices(timeout, plan.ReadAlias)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(indices) != 1 {
+			return nil, fmt.Errorf("alias %s points to %d indices", plan.ReadAlias, len(indices))
+		}
+		report.SourceIndex = indices[0]
+	}
+
+	if report.TargetIndex == "" {
+		report.TargetIndex = plan.ReadAlias + "_" + time.Now().Format("20060102150405")
+	}
+
+	if _, err := c.IndexCreate(timeout, report.TargetIndex, plan.Settings, plan.Mappings); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if plan.Transform != nil {
+		report.Dropped, err = c.scrollCopy(timeout, report.SourceIndex, report.TargetIndex, plan.BatchSize, plan.Transform)
+	} else {
+		var task *results.TaskResult
+		ctx, cancel := context.Background(), func() {}
+		if plan.CopyTimeout > 0 {
+			ctx, cancel = context.WithTimeout(context.Background(), plan.CopyTimeout)
+		}
+		task, err = c.serverCopy(ctx, timeout, report.SourceIndex, report.TargetIndex, plan.Script)
+		cancel()
+		// 脚本设置ctx.op为noop的文档不会写入目标索引
+		if err == nil && task.Response != nil {
+			report.Dropped = task.Response.Noops
+		}
+	}
+
+	if err == nil {
+		err = c.checkCount(timeout, report)
+	}
+
+	// 复制失败时删除未完成的目标索引，别名仍指向源索引；任务未停止时删除后会被写入自动重建，保留目标索引
+	if errors.Is(err, errCopyRunning) {
+		return report, err
+	}
+
+	if err != nil {
+		if _, deleteErr := c.IndexDelete(timeout, report.TargetIndex); deleteErr != nil {
+			return report, fmt.Errorf("%w, delete target index failed: %s", err, deleteErr)
+		}
+		return report, err
+	}
+
+	actions := []AliasAction{
+		RemoveAlias(report.SourceIndex, plan.ReadAlias),
+		AddAlias(report.TargetIndex, NewAlias(plan.ReadAlias)),
+	}
+
+	if plan.WriteAlias != "" && plan.WriteAlias != plan.ReadAlias {
+		actions = append(actions,
+			RemoveAlias(report.SourceIndex, plan.WriteAlias),
+			AddAlias(report.TargetIndex, NewAlias(plan.WriteAlias).WithWriteIndex(true)),
+		)
+	}
+
+	if _, err = c.AliasesUpdate(timeout, actions...); err != nil {
+		return report, err
+	}
+
+	if plan.DeleteSource {
+		_, err = c.IndexDelete(timeout, report.SourceIndex)
+	}
+
+	return report, err
+}
+
+// serverCopy 以任务方式执行_reindex，单次请求超时不会中断复制，ctx结束时取消任务
+func (c *Connection) serverCopy(ctx context.Context, timeout time.Duration, source, target, script string) (*results.TaskResult, error) {
+	reindex := NewReindex((&Query{}).From(source), target)
+	if script != "" {
+		reindex.WithScript(NewScript(script))
+	}
+
+	started, err := c.Reindex(timeout, reindex, NewDocOptions().Refresh(RefreshTrue).Async())
+	if err != nil {
+		return nil, err
+	}
+
+	task, err := c.WaitForTask(ctx, started.Task, time.Second)
+	if err == nil || (task != nil && task.Completed) {
+		return task, err
+	}
+
+	// 超时或轮询失败时任务仍在运行，取消并等待停止
+	if _, cancelErr := c.taskCancel(timeout, started.Task, true); cancelErr != nil {
+		return task, fmt.Errorf("%w: task:%s %s, cancel failed: %s", errCopyRunning, started.Task, err, cancelErr)
+	}
+
+	return task, err
+}
+
+// scrollCopy 客户端滚动读取源索引，转换后批量写入目标索引
+func (c *Connection) scrollCopy(timeout time.Duration, source, target string, batchSize int, transform TransformFunc) (dropped int64, err error) {
+	if batchSize < 1 {
+		batchSize = defaultReindexBatchSize
+	}
+
+	sr := &results.SearchResult{}
+	resp, err := c.decode(timeout, http.MethodPost, "/"+source+"/_search?scroll="+scrollKeepAlive, `{"size":`+strconv.Itoa(batchSize)+`,"sort":["_doc"]}`, sr)
+	if err != nil {
+		return 0, err
+	}
+
+	if !resp.IsOk() {
+		return 0, resp.Error()
+	}
+
+	defer func() {
+		if sr.ScrollId != "" {
+			_, _ = c.Delete(timeout, "/_search/scroll", `{"scroll_id":"`+sr.ScrollId+`"}`)
+		}
+	}()
+
+	for len(sr.Hits.Hits) > 0 {
+		items := make([]BulkDoc, 0, len(sr.Hits.Hits))
+		for _, hit := range sr.Hits.Hits {
+			doc, ok := transform(hit.Id, hit.Source)
+			if !ok {
+				dropped++
+				continue
+			}
+			items = append(items, IndexDoc(target, hit.Id, doc))
+		}
+
+		if len(items) > 0 {
+			if err = c.bulkNoErrors(timeout, items); err != nil {
+				return dropped, err
+			}
+		}
+
+		scrollId := sr.ScrollId
+		sr = &results.SearchResult{}
+		resp, err = c.decode(timeout, http.MethodPost, "/_search/scroll", `{"scroll":"`+scrollKeepAlive+`","scroll_id":"`+scrollId+`"}`, sr)
+		if err != nil {
+			return dropped, err
+		}
+
+		if !resp.IsOk() {
+			return dropped, resp.Error()
+		}
+	}
+
+	_, err = c.IndexRefresh(timeout, target)
+	return dropped, err
+}
+
+func (c *Connection) bulkNoErrors(timeout time.Duration, items []BulkDoc) error {
+	resp, err := c.DocsBulk(timeout, items...)
+	if err != nil {
+		return err
+	}
+
+	if !resp.IsOk() {
+		return resp.Error()
+	}
+
+	br, err := resp.UnmarshalBulkResult()
+	if err != nil {
+		return err
+	}
+
+	if br.HasErrors() {
+		return errors.New("bulk has errors")
+	}
+
+	return nil
+}
+
+func (c *Connection) checkCount(timeout time.Duration, report *ReindexReport) (err error) {
+	if report.SourceCount, err = (&Query{}).From(report.SourceIndex).Count(timeout, c); err != nil {
+		return err
+	}
+
+	if report.TargetCount, err = (&Query{}).From(report.TargetIndex).Count(timeout, c); err != nil {
+		return err
+	}
+
+	if report.SourceCount-report.Dropped != report.TargetCount {
+		return fmt.Errorf("%w source:%d dropped:%d target:%d", ErrReindexCountMismatch, report.SourceCount, report.Dropped, report.TargetCount)
+	}
+
+	return nil
+}
@@ -29,6 +29,10 @@ func (r *Response) Error() (err error) {
 		return nil
 	}
 
+	if r.Is(http.StatusConflict) {
+		return &ConflictError{Body: r.Body}
+	}
+
 	errMsg := strings.Builder{}
 	status := strconv.Itoa(r.Status)
 
@@ -0,0 +1,9 @@
+package results
+
+type AcknowledgedResult struct {
+	Acknowledged       bool `json:"acknowledged"`
+	ShardsAcknowledged bool `json:"shards_acknowledged"`
+	Indices            map[string]struct {
+		Closed bool `json:"closed"`
+	} `json:"indices,omitempty"`
+}
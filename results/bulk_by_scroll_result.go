@@ -0,0 +1,46 @@
+package results
+
+// BulkByScrollResult result of update by query, delete by query and reindex,
+// only Task is set when request is sent with wait_for_completion=false
+type BulkByScrollResult struct {
+	Took             int64 `json:"took"`
+	TimedOut         bool  `json:"timed_out"`
+	Total            int64 `json:"total"`
+	Updated          int64 `json:"updated"`
+	Created          int64 `json:"created"`
+	Deleted          int64 `json:"deleted"`
+	Batches          int64 `json:"batches"`
+	VersionConflicts int64 `json:"version_conflicts"`
+	Noops            int64 `json:"noops"`
+	Retries          struct {
+		Bulk   int64 `json:"bulk"`
+		Search int64 `json:"search"`
+	} `json:"retries"`
+	ThrottledMillis   int64           `json:"throttled_millis"`
+	RequestsPerSecond float64         `json:"requests_per_second"`
+	Failures          []ScrollFailure `json:"failures"`
+	Task              string          `json:"task"`
+}
+
+type ScrollFailure struct {
+	Index  string `json:"index"`
+	Id     string `json:"id"`
+	Status int    `json:"status"`
+	Cause  struct {
+		Type   string `json:"type"`
+		Reason string `json:"reason"`
+	} `json:"cause"`
+}
+
+func (bsr *BulkByScrollResult) HasFailures() bool {
+	return len(bsr.Failures) > 0
+}
+
+// TookMillis server side took in milliseconds, -1 when request is sent with wait_for_completion=false
+func (bsr *BulkByScrollResult) TookMillis() int64 {
+	if bsr.Task != "" {
+		return -1
+	}
+
+	return bsr.Took
+}
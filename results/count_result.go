@@ -0,0 +1,6 @@
+package results
+
+type CountResult struct {
+	Count  int64  `json:"count"`
+	Shards Shards `json:"_shards"`
+}
@@ -9,4 +9,6 @@ type DocumentResult struct {
 	PrimaryTerm int64          `json:"_primary_term"`
 	Found       bool           `json:"found"`
 	Source      base.JsonParam `json:"_source"`
+	// Fields stored fields, values are always arrays
+	Fields base.JsonParam `json:"fields"`
 }
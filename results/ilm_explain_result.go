@@ -0,0 +1,28 @@
+package results
+
+import "github.com/grpc-boot/base"
+
+type IlmExplainResult struct {
+	Indices map[string]IlmIndexExplain `json:"indices"`
+}
+
+type IlmIndexExplain struct {
+	Index               string         `json:"index"`
+	Managed             bool           `json:"managed"`
+	Policy              string         `json:"policy"`
+	LifecycleDateMillis int64          `json:"lifecycle_date_millis"`
+	Age                 string         `json:"age"`
+	Phase               string         `json:"phase"`
+	PhaseTimeMillis     int64          `json:"phase_time_millis"`
+	Action              string         `json:"action"`
+	ActionTimeMillis    int64          `json:"action_time_millis"`
+	Step                string         `json:"step"`
+	StepTimeMillis      int64          `json:"step_time_millis"`
+	FailedStep          string         `json:"failed_step"`
+	StepInfo            base.JsonParam `json:"step_info"`
+}
+
+// IsError index is stuck in error step, see FailedStep and StepInfo
+func (ie IlmIndexExplain) IsError() bool {
+	return ie.Step == "ERROR"
+}
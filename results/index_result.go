@@ -1,10 +1,42 @@
 package results
 
+import "github.com/grpc-boot/base"
+
+const (
+	ResultCreated  = `created`
+	ResultUpdated  = `updated`
+	ResultDeleted  = `deleted`
+	ResultNotFound = `not_found`
+	ResultNoop     = `noop`
+)
+
 type IndexResult struct {
 	DocumentHeader
 
-	SeqNo       int64  `json:"_seq_no"`
-	PrimaryTerm int64  `json:"_primary_term"`
-	Shards      Shards `json:"_shards"`
-	Result      string `json:"result"`
+	SeqNo       int64     `json:"_seq_no"`
+	PrimaryTerm int64     `json:"_primary_term"`
+	Shards      Shards    `json:"_shards"`
+	Result      string    `json:"result"`
+	Get         *IndexGet `json:"get,omitempty"`
+}
+
+// IndexGet source of updated document, returned when _source is requested on update
+type IndexGet struct {
+	SeqNo       int64          `json:"_seq_no"`
+	PrimaryTerm int64          `json:"_primary_term"`
+	Found       bool           `json:"found"`
+	Source      base.JsonParam `json:"_source"`
+}
+
+// IsNoop update did not change document
+func (ir *IndexResult) IsNoop() bool {
+	return ir.Result == ResultNoop
+}
+
+func (ir *IndexResult) Source() base.JsonParam {
+	if ir.Get == nil {
+		return nil
+	}
+
+	return ir.Get.Source
 }
@@ -0,0 +1,51 @@
+package results
+
+type IndexStatsResult struct {
+	Shards  Shards                `json:"_shards"`
+	All     IndexStats            `json:"_all"`
+	Indices map[string]IndexStats `json:"indices"`
+}
+
+type IndexStats struct {
+	Uuid      string     `json:"uuid"`
+	Health    string     `json:"health"`
+	Status    string     `json:"status"`
+	Primaries StatsGroup `json:"primaries"`
+	Total     StatsGroup `json:"total"`
+}
+
+type StatsGroup struct {
+	Docs struct {
+		Count   int64 `json:"count"`
+		Deleted int64 `json:"deleted"`
+	} `json:"docs"`
+	Store struct {
+		SizeInBytes int64 `json:"size_in_bytes"`
+	} `json:"store"`
+	Indexing struct {
+		IndexTotal        int64 `json:"index_total"`
+		IndexTimeInMillis int64 `json:"index_time_in_millis"`
+		IndexFailed       int64 `json:"index_failed"`
+		DeleteTotal       int64 `json:"delete_total"`
+	} `json:"indexing"`
+	Search struct {
+		QueryTotal        int64 `json:"query_total"`
+		QueryTimeInMillis int64 `json:"query_time_in_millis"`
+		FetchTotal        int64 `json:"fetch_total"`
+		ScrollCurrent     int64 `json:"scroll_current"`
+	} `json:"search"`
+	Segments struct {
+		Count         int64 `json:"count"`
+		MemoryInBytes int64 `json:"memory_in_bytes"`
+	} `json:"segments"`
+	Refresh struct {
+		Total int64 `json:"total"`
+	} `json:"refresh"`
+	Flush struct {
+		Total int64 `json:"total"`
+	} `json:"flush"`
+	Merges struct {
+		Current int64 `json:"current"`
+		Total   int64 `json:"total"`
+	} `json:"merges"`
+}
@@ -2,27 +2,62 @@ package results
 
 import (
 	"github.com/grpc-boot/base"
+	jsoniter "github.com/json-iterator/go"
 )
 
 type SearchResult struct {
-	Took    int64 `json:"took"`
-	Timeout bool  `json:"timed_out"`
-	Hits    struct {
+	ScrollId string `json:"_scroll_id,omitempty"`
+	Took     int64  `json:"took"`
+	Timeout  bool   `json:"timed_out"`
+	Hits     struct {
 		Total struct {
 			Value    int64  `json:"value"`
 			Relation string `json:"relation"`
 		} `json:"total"`
 
-		Hits []struct {
-			DocumentHeader
-
-			Score  float64        `json:"_score"`
-			Sort   []interface{}  `json:"sort"`
-			Source base.JsonParam `json:"_source"`
-		} `json:"hits"`
+		Hits []SearchHit `json:"hits"`
 	} `json:"hits"`
 }
 
+// SearchHit RawSource keeps _source as returned, numbers in Source are float64
+type SearchHit struct {
+	DocumentHeader
+
+	SeqNo       int64               `json:"_seq_no"`
+	PrimaryTerm int64               `json:"_primary_term"`
+	Score       float64             `json:"_score"`
+	Sort        []interface{}       `json:"sort"`
+	Source      base.JsonParam      `json:"_source"`
+	RawSource   jsoniter.RawMessage `json:"-"`
+}
+
+func (sh *SearchHit) UnmarshalJSON(data []byte) error {
+	// 外层Source优先于内嵌的同名字段，保留原始字节
+	type hit SearchHit
+	var raw struct {
+		hit
+
+		Source jsoniter.RawMessage `json:"_source"`
+	}
+
+	if err := base.JsonUnmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*sh = SearchHit(raw.hit)
+	sh.RawSource = raw.Source
+	if len(raw.Source) < 1 {
+		return nil
+	}
+
+	return base.JsonUnmarshal(raw.Source, &sh.Source)
+}
+
+// TookMillis server side took in milliseconds
+func (sr *SearchResult) TookMillis() int64 {
+	return sr.Took
+}
+
 func (sr *SearchResult) ToRows() *RowsResult {
 	if sr == nil {
 		return nil
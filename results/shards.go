@@ -1,7 +1,28 @@
 package results
 
 type Shards struct {
-	Total      int64 `json:"total"`
-	Failed     int64 `json:"failed"`
-	Successful int64 `json:"successful"`
+	Total      int64          `json:"total"`
+	Failed     int64          `json:"failed"`
+	Successful int64          `json:"successful"`
+	Failures   []ShardFailure `json:"failures,omitempty"`
+}
+
+type ShardFailure struct {
+	Index  string `json:"index"`
+	Shard  int64  `json:"shard"`
+	Node   string `json:"node"`
+	Status string `json:"status"`
+	Reason struct {
+		Type   string `json:"type"`
+		Reason string `json:"reason"`
+	} `json:"reason"`
+}
+
+// ShardsResult result of refresh, flush, forcemerge and clear cache
+type ShardsResult struct {
+	Shards Shards `json:"_shards"`
+}
+
+func (sr *ShardsResult) HasFailures() bool {
+	return sr.Shards.Failed > 0
 }
@@ -0,0 +1,69 @@
+package results
+
+import "strconv"
+
+type TaskResult struct {
+	Completed bool                `json:"completed"`
+	Task      TaskInfo            `json:"task"`
+	Response  *BulkByScrollResult `json:"response"`
+	Error     *TaskError          `json:"error"`
+}
+
+type TaskError struct {
+	Type     string `json:"type"`
+	Reason   string `json:"reason"`
+	CausedBy struct {
+		Type   string `json:"type"`
+		Reason string `json:"reason"`
+	} `json:"caused_by"`
+}
+
+type TaskInfo struct {
+	Node               string     `json:"node"`
+	Id                 int64      `json:"id"`
+	Type               string     `json:"type"`
+	Action             string     `json:"action"`
+	Status             TaskStatus `json:"status"`
+	Description        string     `json:"description"`
+	StartTimeInMillis  int64      `json:"start_time_in_millis"`
+	RunningTimeInNanos int64      `json:"running_time_in_nanos"`
+	Cancellable        bool       `json:"cancellable"`
+	Cancelled          bool       `json:"cancelled"`
+	ParentTaskId       string     `json:"parent_task_id"`
+}
+
+// TaskId id used by task apis
+func (ti TaskInfo) TaskId() string {
+	return ti.Node + ":" + strconv.FormatInt(ti.Id, 10)
+}
+
+// TaskStatus progress of reindex, update by query and delete by query tasks
+type TaskStatus struct {
+	Total             int64   `json:"total"`
+	Updated           int64   `json:"updated"`
+	Created           int64   `json:"created"`
+	Deleted           int64   `json:"deleted"`
+	Batches           int64   `json:"batches"`
+	VersionConflicts  int64   `json:"version_conflicts"`
+	Noops             int64   `json:"noops"`
+	ThrottledMillis   int64   `json:"throttled_millis"`
+	RequestsPerSecond float64 `json:"requests_per_second"`
+}
+
+type TaskListResult struct {
+	Nodes map[string]struct {
+		Name  string              `json:"name"`
+		Tasks map[string]TaskInfo `json:"tasks"`
+	} `json:"nodes"`
+}
+
+func (tlr *TaskListResult) Tasks() []TaskInfo {
+	var tasks []TaskInfo
+	for _, node := range tlr.Nodes {
+		for _, task := range node.Tasks {
+			tasks = append(tasks, task)
+		}
+	}
+
+	return tasks
+}
@@ -0,0 +1,124 @@
+package results
+
+import (
+	"github.com/grpc-boot/base"
+)
+
+// Hit SeqNo and PrimaryTerm are returned when query is built with Query.WithSeqNo
+type Hit[T any] struct {
+	DocumentHeader
+
+	SeqNo       int64         `json:"_seq_no,omitempty"`
+	PrimaryTerm int64         `json:"_primary_term,omitempty"`
+	Score       float64       `json:"_score"`
+	Sort        []interface{} `json:"sort"`
+	Source      T             `json:"_source"`
+}
+
+type TypedSearchResult[T any] struct {
+	Took    int64 `json:"took"`
+	Timeout bool  `json:"timed_out"`
+	Hits    struct {
+		Total struct {
+			Value    int64  `json:"value"`
+			Relation string `json:"relation"`
+		} `json:"total"`
+
+		Hits []Hit[T] `json:"hits"`
+	} `json:"hits"`
+}
+
+type Document[T any] struct {
+	DocumentHeader
+
+	SeqNo       int64 `json:"_seq_no"`
+	PrimaryTerm int64 `json:"_primary_term"`
+	Found       bool  `json:"found"`
+	Source      T     `json:"_source"`
+}
+
+type Documents[T any] struct {
+	Docs []Document[T] `json:"docs"`
+}
+
+// DecodeHits decode source of hits into T, metadata of hits are kept
+func DecodeHits[T any](sr *SearchResult) ([]Hit[T], error) {
+	if sr == nil || len(sr.Hits.Hits) < 1 {
+		return nil, nil
+	}
+
+	hits := make([]Hit[T], len(sr.Hits.Hits))
+	for index, item := range sr.Hits.Hits {
+		hits[index] = Hit[T]{
+			DocumentHeader: item.DocumentHeader,
+			SeqNo:          item.SeqNo,
+			PrimaryTerm:    item.PrimaryTerm,
+			Score:          item.Score,
+			Sort:           item.Sort,
+		}
+
+		// 使用原始字节解码，避免经过float64丢失大整数精度
+		source := []byte(item.RawSource)
+		if len(source) < 1 {
+			source = item.Source.JsonMarshal()
+		}
+
+		if err := base.JsonUnmarshal(source, &hits[index].Source); err != nil {
+			return nil, err
+		}
+	}
+
+	return hits, nil
+}
+
+// TookMillis server side took in milliseconds
+func (ts *TypedSearchResult[T]) TookMillis() int64 {
+	return ts.Took
+}
+
+func (ts *TypedSearchResult[T]) Sources() []T {
+	if ts == nil || len(ts.Hits.Hits) < 1 {
+		return nil
+	}
+
+	rows := make([]T, len(ts.Hits.Hits))
+	for index, hit := range ts.Hits.Hits {
+		rows[index] = hit.Source
+	}
+
+	return rows
+}
+
+func (d *Documents[T]) ToRowsMap() map[string]T {
+	if len(d.Docs) < 1 {
+		return nil
+	}
+
+	rows := make(map[string]T, len(d.Docs))
+	for _, doc := range d.Docs {
+		if !doc.Found {
+			continue
+		}
+
+		rows[doc.Id] = doc.Source
+	}
+
+	return rows
+}
+
+func (d *Documents[T]) ToRows() []T {
+	if len(d.Docs) < 1 {
+		return nil
+	}
+
+	rows := make([]T, 0, len(d.Docs))
+	for _, doc := range d.Docs {
+		if !doc.Found {
+			continue
+		}
+
+		rows = append(rows, doc.Source)
+	}
+
+	return rows
+}
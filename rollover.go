@@ -0,0 +1,120 @@
+package elastic
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/grpc-boot/elastic/results"
+
+	"github.com/grpc-boot/base"
+)
+
+const (
+	resizeShrink = `_shrink`
+	resizeSplit  = `_split`
+	resizeClone  = `_clone`
+)
+
+// RolloverConditions rollover happens when any condition is met, no condition means rollover unconditionally
+// link: https://www.elastic.co/guide/en/elasticsearch/reference/current/indices-rollover-index.html
+type RolloverConditions struct {
+	MaxAge              string `json:"max_age,omitempty"`
+	MaxDocs             int64  `json:"max_docs,omitempty"`
+	MaxSize             string `json:"max_size,omitempty"`
+	MaxPrimaryShardSize string `json:"max_primary_shard_size,omitempty"`
+	MaxPrimaryShardDocs int64  `json:"max_primary_shard_docs,omitempty"`
+}
+
+// Rollover conditions and settings, mappings and aliases of new index
+type Rollover struct {
+	Conditions *RolloverConditions `json:"conditions,omitempty"`
+	Settings   *Settings           `json:"settings,omitempty"`
+	Mappings   *Mappings           `json:"mappings,omitempty"`
+	Aliases    map[string]*Alias   `json:"aliases,omitempty"`
+}
+
+func (r *Rollover) WithAliases(aliases ...*Alias) *Rollover {
+	r.Aliases = aliasesMap(aliases)
+	return r
+}
+
+func (r *Rollover) Marshal() []byte {
+	data, _ := base.JsonMarshal(r)
+	return data
+}
+
+// IndexRollover roll alias over to newIndex, empty newIndex lets server increase the number suffix of old index
+// link: https://www.elastic.co/guide/en/elasticsearch/reference/current/indices-rollover-index.html
+func (c *Connection) IndexRollover(timeout time.Duration, alias string, newIndex string, rollover *Rollover) (*results.RolloverResult, error) {
+	return c.rollover(timeout, alias, newIndex, rollover, false)
+}
+
+// IndexRolloverDryRun check conditions without rollover
+func (c *Connection) IndexRolloverDryRun(timeout time.Duration, alias string, newIndex string, rollover *Rollover) (*results.RolloverResult, error) {
+	return c.rollover(timeout, alias, newIndex, rollover, true)
+}
+
+func (c *Connection) rollover(timeout time.Duration, alias string, newIndex string, rollover *Rollover, dryRun bool) (*results.RolloverResult, error) {
+	path := "/" + alias + "/_rollover"
+	if newIndex != "" {
+		path += "/" + newIndex
+	}
+
+	if dryRun {
+		path += "?dry_run=true"
+	}
+
+	if rollover == nil {
+		rollover = &Rollover{}
+	}
+
+	result := &results.RolloverResult{}
+	resp, err := c.decode(timeout, http.MethodPost, path, base.Bytes2String(rollover.Marshal()), result)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.IsOk() {
+		return result, nil
+	}
+
+	return nil, resp.Error()
+}
+
+// IndexShrink source index must be read only and have a copy of every shard on one node
+// link: https://www.elastic.co/guide/en/elasticsearch/reference/current/indices-shrink-index.html
+func (c *Connection) IndexShrink(timeout time.Duration, source, target string, settings *Settings, aliases ...*Alias) (*results.ResizeResult, error) {
+	return c.resize(timeout, resizeShrink, source, target, settings, aliases)
+}
+
+// IndexSplit source index must be read only, number of shards of target must be a multiple of source
+// link: https://www.elastic.co/guide/en/elasticsearch/reference/current/indices-split-index.html
+func (c *Connection) IndexSplit(timeout time.Duration, source, target string, settings *Settings, aliases ...*Alias) (*results.ResizeResult, error) {
+	return c.resize(timeout, resizeSplit, source, target, settings, aliases)
+}
+
+// IndexClone source index must be read only
+// link: https://www.elastic.co/guide/en/elasticsearch/reference/current/indices-clone-index.html
+func (c *Connection) IndexClone(timeout time.Duration, source, target string, settings *Settings, aliases ...*Alias) (*results.ResizeResult, error) {
+	return c.resize(timeout, resizeClone, source, target, settings, aliases)
+}
+
+func (c *Connection) resize(timeout time.Duration, action, source, target string, settings *Settings, aliases []*Alias) (*results.ResizeResult, error) {
+	body, err := base.JsonMarshal(&Template{Settings: settings, Aliases: aliasesMap(aliases)})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &results.ResizeResult{}
+	resp, err := c.decode(timeout, http.MethodPost, "/"+source+"/"+action+"/"+target, base.Bytes2String(body), result)
+	if err != nil {
+		return nil, err
+	}
+
+	if !resp.IsOk() {
+		return nil, resp.Error()
+	}
+
+	result.SourceIndex = source
+	return result, nil
+}
@@ -0,0 +1,319 @@
+package elastic
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grpc-boot/base"
+)
+
+// staticSettings can not be changed on an open index, shards, sort and routing partition need reindex
+var staticSettings = []string{"number_of_shards", "codec", "sort.", "routing_partition_size", "analysis."}
+
+var reindexSettings = []string{"number_of_shards", "sort.", "routing_partition_size"}
+
+// FieldDiff difference of field between declared and live mappings, Path is dot separated
+type FieldDiff struct {
+	Path     string    `json:"path"`
+	Reason   string    `json:"reason,omitempty"`
+	Declared *Property `json:"declared,omitempty"`
+	Live     *Property `json:"live,omitempty"`
+}
+
+// SettingDiff difference of setting between declared and live settings, Key is dot separated
+type SettingDiff struct {
+	Key      string      `json:"key"`
+	Declared interface{} `json:"declared"`
+	Live     interface{} `json:"live"`
+	Static   bool        `json:"static"`
+}
+
+// SchemaDiff
+// Added and Updated fields are safe to apply through MappingsAlter with AlterMappings,
+// Changed fields need reindex, Removed fields only exist on cluster
+type SchemaDiff struct {
+	Added    []FieldDiff   `json:"added"`
+	Updated  []FieldDiff   `json:"updated"`
+	Changed  []FieldDiff   `json:"changed"`
+	Removed  []FieldDiff   `json:"removed"`
+	Settings []SettingDiff `json:"settings"`
+
+	alter map[string]*Property
+}
+
+func (sd *SchemaDiff) HasDrift() bool {
+	return len(sd.Added) > 0 || len(sd.Updated) > 0 || len(sd.Changed) > 0 || len(sd.Removed) > 0 || len(sd.Settings) > 0
+}
+
+// NeedReindex changed field types or static shard/sort/routing settings
+func (sd *SchemaDiff) NeedReindex() bool {
+	if len(sd.Changed) > 0 {
+		return true
+	}
+
+	for _, setting := range sd.Settings {
+		if hasPrefix(setting.Key, reindexSettings) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AlterMappings mappings of added and updated fields, nil when nothing to alter
+func (sd *SchemaDiff) AlterMappings() *Mappings {
+	if len(sd.alter) < 1 {
+		return nil
+	}
+
+	return &Mappings{Properties: sd.alter}
+}
+
+func (sd *SchemaDiff) Marshal() []byte {
+	data, _ := base.JsonMarshal(sd)
+	return data
+}
+
+// DiffSchema compare declared settings and mappings with live ones, nil declared part is skipped
+func DiffSchema(declaredSettings, liveSettings *Settings, declaredMappings, liveMappings *Mappings) *SchemaDiff {
+	sd := &SchemaDiff{}
+
+	if declaredMappings != nil {
+		if liveMappings == nil {
+			liveMappings = &Mappings{}
+		}
+		sd.alter = sd.diffProperties("", declaredMappings.Properties, liveMappings.Properties)
+	}
+
+	if declaredSettings != nil {
+		if liveSettings == nil {
+			liveSettings = &Settings{}
+		}
+		sd.diffSettings(declaredSettings, liveSettings)
+	}
+
+	return sd
+}
+
+// SchemaDrift compare declared settings and mappings with live index, index can be alias or pattern,
+// result is keyed by concrete index name
+func (c *Connection) SchemaDrift(timeout time.Duration, index string, settings *Settings, mappings *Mappings) (map[string]*SchemaDiff, error) {
+	liveMappings, err := c.IndexMappings(timeout, index)
+	if err != nil {
+		return nil, err
+	}
+
+	liveSettings, err := c.IndexSettings(timeout, index)
+	if err != nil {
+		return nil, err
+	}
+
+	diffs := make(map[string]*SchemaDiff, len(liveMappings))
+	for name, live := range liveMappings {
+		diffs[name] = DiffSchema(settings, liveSettings[name], mappings, live)
+	}
+
+	return diffs, nil
+}
+
+func (sd *SchemaDiff) diffProperties(prefix string, declared, live map[string]*Property) (alter map[string]*Property) {
+	for _, name := range sortedNames(declared) {
+		var (
+			dp     = declared[name]
+			lp, ok = live[name]
+			path   = prefix + name
+		)
+
+		if !ok {
+			sd.Added = append(sd.Added, FieldDiff{Path: path, Declared: dp})
+			alter = addAlter(alter, name, dp)
+			continue
+		}
+
+		if reason := incompatible(dp, lp); reason != "" {
+			sd.Changed = append(sd.Changed, FieldDiff{Path: path, Reason: reason, Declared: dp, Live: lp})
+			continue
+		}
+
+		var (
+			reason     = updatable(dp, lp)
+			properties = sd.diffProperties(path+".", dp.Properties, lp.Properties)
+			fields     = sd.diffProperties(path+".", dp.Fields, lp.Fields)
+		)
+
+		if reason != "" {
+			sd.Updated = append(sd.Updated, FieldDiff{Path: path, Reason: reason, Declared: dp, Live: lp})
+		}
+
+		if reason != "" || properties != nil || fields != nil {
+			prop := *dp
+			prop.Properties, prop.Fields = properties, fields
+			alter = addAlter(alter, name, &prop)
+		}
+	}
+
+	for _, name := range sortedNames(live) {
+		if _, ok := declared[name]; !ok {
+			sd.Removed = append(sd.Removed, FieldDiff{Path: prefix + name, Live: live[name]})
+		}
+	}
+
+	return
+}
+
+func (sd *SchemaDiff) diffSettings(declared, live *Settings) {
+	var (
+		declaredValues = flattenJson(declared.Marshal())
+		liveValues     = flattenJson(live.Marshal())
+		keys           = make([]string, 0, len(declaredValues))
+	)
+
+	for key := range declaredValues {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if declaredValues[key] == liveValues[key] {
+			continue
+		}
+
+		sd.Settings = append(sd.Settings, SettingDiff{
+			Key:      key,
+			Declared: declaredValues[key],
+			Live:     liveValues[key],
+			Static:   hasPrefix(key, staticSettings),
+		})
+	}
+}
+
+// incompatible 修改后需要重建索引的参数
+func incompatible(declared, live *Property) string {
+	switch {
+	case propertyType(declared) != propertyType(live):
+		return "type " + propertyType(live) + " => " + propertyType(declared)
+	case declared.Format != live.Format:
+		return "format " + live.Format + " => " + declared.Format
+	case declared.Analyzer != live.Analyzer:
+		return "analyzer " + live.Analyzer + " => " + declared.Analyzer
+	case declared.Normalizer != live.Normalizer:
+		return "normalizer " + live.Normalizer + " => " + declared.Normalizer
+	case !sameBool(declared.Index, live.Index, true):
+		return "index"
+	case !sameBool(declared.DocValues, live.DocValues, true):
+		return "doc_values"
+	case !sameBool(declared.Enabled, live.Enabled, true):
+		return "enabled"
+	case !sameJson(declared.NullValue, live.NullValue):
+		return "null_value"
+	case !sameJson(declared.CopyTo, live.CopyTo):
+		return "copy_to"
+	case declared.ScalingFactor != live.ScalingFactor:
+		return "scaling_factor"
+	case declared.Dims != live.Dims:
+		return "dims"
+	case declared.Path != live.Path:
+		return "path"
+	}
+
+	return ""
+}
+
+// updatable 可以通过MappingsAlter修改的参数
+func updatable(declared, live *Property) string {
+	var reasons []string
+
+	if declared.IgnoreAbove != live.IgnoreAbove {
+		reasons = append(reasons, "ignore_above")
+	}
+
+	if declared.SearchAnalyzer != "" && declared.SearchAnalyzer != live.SearchAnalyzer {
+		reasons = append(reasons, "search_analyzer")
+	}
+
+	return strings.Join(reasons, ",")
+}
+
+func propertyType(p *Property) string {
+	if p.Type == "" && p.Properties != nil {
+		return TypeObject
+	}
+
+	return p.Type
+}
+
+func sameBool(a, b *bool, defaultValue bool) bool {
+	av, bv := defaultValue, defaultValue
+	if a != nil {
+		av = *a
+	}
+
+	if b != nil {
+		bv = *b
+	}
+
+	return av == bv
+}
+
+func sameJson(a, b interface{}) bool {
+	ad, _ := base.JsonEncode(a)
+	bd, _ := base.JsonEncode(b)
+	return ad == bd
+}
+
+func addAlter(alter map[string]*Property, name string, prop *Property) map[string]*Property {
+	if alter == nil {
+		alter = make(map[string]*Property)
+	}
+
+	alter[name] = prop
+	return alter
+}
+
+func sortedNames(properties map[string]*Property) []string {
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+func hasPrefix(key string, prefixList []string) bool {
+	for _, prefix := range prefixList {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// flattenJson 将对象展开为点分隔的键，值统一转换为字符串比较，原因见settingsFromIndex
+func flattenJson(data []byte) map[string]string {
+	var value map[string]interface{}
+	_ = base.JsonUnmarshal(data, &value)
+
+	values := make(map[string]string)
+	flattenValue("", value, values)
+	return values
+}
+
+func flattenValue(prefix string, value interface{}, values map[string]string) {
+	if obj, ok := value.(map[string]interface{}); ok {
+		for key, item := range obj {
+			flattenValue(prefix+key+".", item, values)
+		}
+		return
+	}
+
+	key := strings.TrimSuffix(prefix, ".")
+	if str, ok := value.(string); ok {
+		values[key] = str
+		return
+	}
+
+	values[key], _ = base.JsonEncode(value)
+}
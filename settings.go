@@ -1,10 +1,227 @@
 package elastic
 
-import "github.com/grpc-boot/base"
+import (
+	"strconv"
 
+	"github.com/grpc-boot/base"
+)
+
+const (
+	CodecDefault         = `default`
+	CodecBestCompression = `best_compression`
+
+	AnalyzerTypeCustom   = `custom`
+	NormalizerTypeCustom = `custom`
+)
+
+// Settings
+// link: https://www.elastic.co/guide/en/elasticsearch/reference/current/index-modules.html
 type Settings struct {
-	NumberOfShards   int `json:"number_of_shards"`
-	NumberOfReplicas int `json:"number_of_replicas,omitempty"`
+	NumberOfShards       int        `json:"number_of_shards,omitempty"`
+	NumberOfReplicas     int        `json:"number_of_replicas,omitempty"`
+	RefreshInterval      string     `json:"refresh_interval,omitempty"`
+	MaxResultWindow      int64      `json:"max_result_window,omitempty"`
+	Codec                string     `json:"codec,omitempty"`
+	RoutingPartitionSize int        `json:"routing_partition_size,omitempty"`
+	Sort                 *IndexSort `json:"sort,omitempty"`
+	Analysis             *Analysis  `json:"analysis,omitempty"`
+	Lifecycle            *Lifecycle `json:"lifecycle,omitempty"`
+}
+
+// Lifecycle ilm policy managing the index, RolloverAlias is required by rollover action
+// link: https://www.elastic.co/guide/en/elasticsearch/reference/current/ilm-settings.html
+type Lifecycle struct {
+	Name          string `json:"name,omitempty"`
+	RolloverAlias string `json:"rollover_alias,omitempty"`
+}
+
+// IndexSort
+// link: https://www.elastic.co/guide/en/elasticsearch/reference/current/index-modules-index-sorting.html
+type IndexSort struct {
+	Field   []string `json:"field,omitempty"`
+	Order   []string `json:"order,omitempty"`
+	Mode    []string `json:"mode,omitempty"`
+	Missing []string `json:"missing,omitempty"`
+}
+
+// Analysis
+// link: https://www.elastic.co/guide/en/elasticsearch/reference/current/analysis-custom-analyzer.html
+type Analysis struct {
+	Analyzer   map[string]*Analyzer      `json:"analyzer,omitempty"`
+	Normalizer map[string]*Normalizer    `json:"normalizer,omitempty"`
+	Tokenizer  map[string]base.JsonParam `json:"tokenizer,omitempty"`
+	Filter     map[string]base.JsonParam `json:"filter,omitempty"`
+	CharFilter map[string]base.JsonParam `json:"char_filter,omitempty"`
+}
+
+type Analyzer struct {
+	Type       string   `json:"type"`
+	Tokenizer  string   `json:"tokenizer,omitempty"`
+	Filter     []string `json:"filter,omitempty"`
+	CharFilter []string `json:"char_filter,omitempty"`
+}
+
+type Normalizer struct {
+	Type       string   `json:"type"`
+	Filter     []string `json:"filter,omitempty"`
+	CharFilter []string `json:"char_filter,omitempty"`
+}
+
+func CustomAnalyzer(tokenizer string, filters ...string) *Analyzer {
+	return &Analyzer{Type: AnalyzerTypeCustom, Tokenizer: tokenizer, Filter: filters}
+}
+
+func (a *Analyzer) WithCharFilter(charFilters ...string) *Analyzer {
+	a.CharFilter = charFilters
+	return a
+}
+
+func CustomNormalizer(filters ...string) *Normalizer {
+	return &Normalizer{Type: NormalizerTypeCustom, Filter: filters}
+}
+
+func (n *Normalizer) WithCharFilter(charFilters ...string) *Normalizer {
+	n.CharFilter = charFilters
+	return n
+}
+
+func (s *Settings) WithRefreshInterval(interval string) *Settings {
+	s.RefreshInterval = interval
+	return s
+}
+
+func (s *Settings) WithMaxResultWindow(value int64) *Settings {
+	s.MaxResultWindow = value
+	return s
+}
+
+func (s *Settings) WithCodec(codec string) *Settings {
+	s.Codec = codec
+	return s
+}
+
+func (s *Settings) WithRoutingPartitionSize(size int) *Settings {
+	s.RoutingPartitionSize = size
+	return s
+}
+
+func (s *Settings) WithLifecycle(policy string, rolloverAlias string) *Settings {
+	s.Lifecycle = &Lifecycle{Name: policy, RolloverAlias: rolloverAlias}
+	return s
+}
+
+// WithSort index sorting, can only be set on index creation
+func (s *Settings) WithSort(order ...OrderBy) *Settings {
+	s.Sort = &IndexSort{
+		Field: make([]string, len(order)),
+		Order: make([]string, len(order)),
+	}
+
+	for index, item := range order {
+		s.Sort.Field[index] = item.field
+		s.Sort.Order[index] = item.order
+	}
+
+	return s
+}
+
+func (s *Settings) analysis() *Analysis {
+	if s.Analysis == nil {
+		s.Analysis = &Analysis{}
+	}
+	return s.Analysis
+}
+
+func (s *Settings) AddAnalyzer(name string, analyzer *Analyzer) *Settings {
+	a := s.analysis()
+	if a.Analyzer == nil {
+		a.Analyzer = make(map[string]*Analyzer)
+	}
+
+	a.Analyzer[name] = analyzer
+	return s
+}
+
+func (s *Settings) AddNormalizer(name string, normalizer *Normalizer) *Settings {
+	a := s.analysis()
+	if a.Normalizer == nil {
+		a.Normalizer = make(map[string]*Normalizer)
+	}
+
+	a.Normalizer[name] = normalizer
+	return s
+}
+
+// AddTokenizer add tokenizer of componentType with params, e.g. ngram with min_gram and max_gram
+func (s *Settings) AddTokenizer(name string, componentType string, params base.JsonParam) *Settings {
+	a := s.analysis()
+	if a.Tokenizer == nil {
+		a.Tokenizer = make(map[string]base.JsonParam)
+	}
+
+	a.Tokenizer[name] = analysisComponent(componentType, params)
+	return s
+}
+
+func (s *Settings) AddFilter(name string, componentType string, params base.JsonParam) *Settings {
+	a := s.analysis()
+	if a.Filter == nil {
+		a.Filter = make(map[string]base.JsonParam)
+	}
+
+	a.Filter[name] = analysisComponent(componentType, params)
+	return s
+}
+
+func (s *Settings) AddCharFilter(name string, componentType string, params base.JsonParam) *Settings {
+	a := s.analysis()
+	if a.CharFilter == nil {
+		a.CharFilter = make(map[string]base.JsonParam)
+	}
+
+	a.CharFilter[name] = analysisComponent(componentType, params)
+	return s
+}
+
+func analysisComponent(componentType string, params base.JsonParam) base.JsonParam {
+	component := make(base.JsonParam, len(params)+1)
+	for key, value := range params {
+		component[key] = value
+	}
+
+	component["type"] = componentType
+	return component
+}
+
+// settingsFromIndex 服务端返回的设置值都是字符串，转换后再解析
+func settingsFromIndex(index base.JsonParam) (*Settings, error) {
+	for _, key := range []string{"number_of_shards", "number_of_replicas", "max_result_window", "routing_partition_size"} {
+		value, ok := index[key].(string)
+		if !ok {
+			continue
+		}
+
+		number, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		index[key] = number
+	}
+
+	if sort, ok := index["sort"].(map[string]interface{}); ok {
+		for key, value := range sort {
+			if str, ok := value.(string); ok {
+				sort[key] = []string{str}
+			}
+		}
+	}
+
+	s := &Settings{}
+	if err := base.JsonUnmarshal(index.JsonMarshal(), s); err != nil {
+		return nil, err
+	}
+
+	return s, nil
 }
 
 func (s *Settings) Marshal() []byte {
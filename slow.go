@@ -0,0 +1,123 @@
+package elastic
+
+import (
+	"time"
+
+	"github.com/grpc-boot/base"
+	"github.com/grpc-boot/base/core/zaplogger"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultSlowBodyMaxLength = 512
+	redactedBody             = `[redacted]`
+)
+
+// SlowRequest request information reported when latency exceeds the slow threshold
+type SlowRequest struct {
+	Method   string        `json:"method"`
+	Path     string        `json:"path"`
+	Status   int           `json:"status"`
+	Took     int64         `json:"took"`
+	Duration time.Duration `json:"duration"`
+	Body     string        `json:"body"`
+}
+
+// SlowHandler receives slow requests, it is called in the request goroutine
+type SlowHandler func(sr SlowRequest)
+
+type slowLog struct {
+	threshold     time.Duration
+	bodyMaxLength int
+	handler       SlowHandler
+}
+
+func newSlowLog(option *Option) *slowLog {
+	if option.SlowThresholdMillisecond < 1 {
+		return nil
+	}
+
+	sl := &slowLog{
+		threshold:     time.Duration(option.SlowThresholdMillisecond) * time.Millisecond,
+		bodyMaxLength: option.SlowBodyMaxLength,
+		handler:       option.SlowHandler,
+	}
+
+	if sl.handler == nil {
+		sl.handler = logSlowRequest
+	}
+
+	return sl
+}
+
+func (sl *slowLog) check(method, path, params string, status int, took func() int64, duration time.Duration) {
+	if sl == nil || duration < sl.threshold {
+		return
+	}
+
+	sl.handler(SlowRequest{
+		Method:   method,
+		Path:     path,
+		Status:   status,
+		Took:     took(),
+		Duration: duration,
+		Body:     sl.truncate(params),
+	})
+}
+
+func (sl *slowLog) truncate(params string) string {
+	switch {
+	case sl.bodyMaxLength < 0:
+		return redactedBody
+	case len(params) <= sl.bodyMaxLength:
+		return params
+	}
+
+	return params[:sl.bodyMaxLength] + "..."
+}
+
+// tookOf 解析服务端耗时，非搜索类响应返回-1
+func tookOf(body []byte) int64 {
+	if len(body) < 1 || body[0] != '{' {
+		return -1
+	}
+
+	var res struct {
+		Took *int64 `json:"took"`
+	}
+
+	if err := base.JsonUnmarshal(body, &res); err != nil || res.Took == nil {
+		return -1
+	}
+
+	return *res.Took
+}
+
+// noTook 请求失败时没有服务端耗时
+func noTook() int64 {
+	return -1
+}
+
+// tookResult 带服务端耗时的解码结果
+type tookResult interface {
+	TookMillis() int64
+}
+
+func tookOfResult(out interface{}) int64 {
+	if tr, ok := out.(tookResult); ok {
+		return tr.TookMillis()
+	}
+
+	return -1
+}
+
+func logSlowRequest(sr SlowRequest) {
+	base.Warn("es slow request",
+		zaplogger.Method(sr.Method),
+		zaplogger.Path(sr.Path),
+		zap.Int("Status", sr.Status),
+		zap.Int64("Took", sr.Took),
+		zaplogger.Duration(sr.Duration),
+		zaplogger.Params(sr.Body),
+	)
+}
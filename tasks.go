@@ -0,0 +1,127 @@
+package elastic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/grpc-boot/elastic/results"
+)
+
+const (
+	taskRequestTimeout  = time.Second * 10
+	defaultPollInterval = time.Second
+)
+
+var (
+	ErrTaskFailed = errors.New("task failed")
+)
+
+// TaskGet id is node:number returned by async apis
+// link: https://www.elastic.co/guide/en/elasticsearch/reference/current/tasks.html
+func (c *Connection) TaskGet(timeout time.Duration, id string) (*results.TaskResult, error) {
+	result := &results.TaskResult{}
+	resp, err := c.decode(timeout, http.MethodGet, "/_tasks/"+id, "", result)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.IsOk() {
+		return result, nil
+	}
+
+	return nil, resp.Error()
+}
+
+// TaskList running tasks with detailed status, actions support wildcard like *reindex, empty actions lists all tasks
+func (c *Connection) TaskList(timeout time.Duration, actions ...string) (*results.TaskListResult, error) {
+	path := "/_tasks?detailed=true"
+	if len(actions) > 0 {
+		path += "&actions=" + url.QueryEscape(strings.Join(actions, ","))
+	}
+
+	result := &results.TaskListResult{}
+	resp, err := c.decode(timeout, http.MethodGet, path, "", result)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.IsOk() {
+		return result, nil
+	}
+
+	return nil, resp.Error()
+}
+
+// TaskCancel only cancellable tasks can be cancelled, documents already written are kept
+func (c *Connection) TaskCancel(timeout time.Duration, id string) (ok bool, err error) {
+	return c.taskCancel(timeout, id, false)
+}
+
+// taskCancel wait为true时等待任务真正停止后返回
+func (c *Connection) taskCancel(timeout time.Duration, id string, wait bool) (ok bool, err error) {
+	path := "/_tasks/" + id + "/_cancel"
+	if wait {
+		path += "?wait_for_completion=true"
+	}
+
+	resp, err := c.Post(timeout, path, "")
+	if err != nil {
+		return
+	}
+
+	if !resp.IsOk() {
+		return false, resp.Error()
+	}
+
+	return true, nil
+}
+
+// WaitForTask poll task until it completes or ctx is done, pollInterval not greater than 0 means 1s,
+// error wraps ErrTaskFailed when task completes with error or failures
+func (c *Connection) WaitForTask(ctx context.Context, id string, pollInterval time.Duration) (*results.TaskResult, error) {
+	if pollInterval <= 0 {
+		pollInterval = defaultPollInterval
+	}
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		timeout := taskRequestTimeout
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < timeout {
+			timeout = time.Until(deadline)
+		}
+
+		result, err := c.TaskGet(timeout, id)
+		if err != nil {
+			return nil, err
+		}
+
+		if result.Completed {
+			if result.Error != nil {
+				return result, fmt.Errorf("%w %s: %s", ErrTaskFailed, result.Error.Type, result.Error.Reason)
+			}
+
+			if result.Response != nil && result.Response.HasFailures() {
+				return result, fmt.Errorf("%w with %d failures", ErrTaskFailed, len(result.Response.Failures))
+			}
+
+			return result, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return result, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
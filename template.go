@@ -0,0 +1,260 @@
+package elastic
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/grpc-boot/base"
+)
+
+// Template settings, mappings and aliases applied to matching indices
+type Template struct {
+	Settings *Settings         `json:"settings,omitempty"`
+	Mappings *Mappings         `json:"mappings,omitempty"`
+	Aliases  map[string]*Alias `json:"aliases,omitempty"`
+}
+
+func (t *Template) WithAliases(aliases ...*Alias) *Template {
+	t.Aliases = aliasesMap(aliases)
+	return t
+}
+
+// IndexTemplate composable index template
+// link: https://www.elastic.co/guide/en/elasticsearch/reference/current/index-templates.html
+type IndexTemplate struct {
+	IndexPatterns []string               `json:"index_patterns"`
+	Template      *Template              `json:"template,omitempty"`
+	ComposedOf    []string               `json:"composed_of,omitempty"`
+	Priority      int                    `json:"priority,omitempty"`
+	Version       int64                  `json:"version,omitempty"`
+	Meta          map[string]interface{} `json:"_meta,omitempty"`
+	DataStream    map[string]interface{} `json:"data_stream,omitempty"`
+}
+
+func (it *IndexTemplate) Marshal() []byte {
+	data, _ := base.JsonMarshal(it)
+	return data
+}
+
+// ComponentTemplate building block of index templates
+// link: https://www.elastic.co/guide/en/elasticsearch/reference/current/indices-component-template.html
+type ComponentTemplate struct {
+	Template *Template              `json:"template"`
+	Version  int64                  `json:"version,omitempty"`
+	Meta     map[string]interface{} `json:"_meta,omitempty"`
+}
+
+func (ct *ComponentTemplate) Marshal() []byte {
+	data, _ := base.JsonMarshal(ct)
+	return data
+}
+
+// SimulatedIndex settings, mappings and aliases resolved for an index name
+type SimulatedIndex struct {
+	Template    *Template `json:"template"`
+	Overlapping []struct {
+		Name          string   `json:"name"`
+		IndexPatterns []string `json:"index_patterns"`
+	} `json:"overlapping"`
+}
+
+// rawTemplate settings按settingsFromIndex转换
+type rawTemplate struct {
+	Settings struct {
+		Index base.JsonParam `json:"index"`
+	} `json:"settings"`
+	Mappings *Mappings         `json:"mappings"`
+	Aliases  map[string]*Alias `json:"aliases"`
+}
+
+func (rt *rawTemplate) toTemplate() (*Template, error) {
+	t := &Template{Mappings: rt.Mappings, Aliases: rt.Aliases}
+
+	if rt.Settings.Index != nil {
+		settings, err := settingsFromIndex(rt.Settings.Index)
+		if err != nil {
+			return nil, err
+		}
+		t.Settings = settings
+	}
+
+	if t.Mappings != nil {
+		t.Mappings.fillNames()
+	}
+
+	for name, alias := range t.Aliases {
+		alias.n = name
+	}
+
+	return t, nil
+}
+
+// IndexTemplatePut create or update index template
+// link: https://www.elastic.co/guide/en/elasticsearch/reference/current/indices-put-template.html
+func (c *Connection) IndexTemplatePut(timeout time.Duration, name string, template *IndexTemplate) (ok bool, err error) {
+	resp, err := c.Put(timeout, "/_index_template/"+name, base.Bytes2String(template.Marshal()))
+	if err != nil {
+		return
+	}
+
+	if !resp.IsOk() {
+		return false, resp.Error()
+	}
+
+	return true, nil
+}
+
+// IndexTemplateGet name can be wildcard pattern, empty name returns all index templates
+// link: https://www.elastic.co/guide/en/elasticsearch/reference/current/indices-get-template.html
+func (c *Connection) IndexTemplateGet(timeout time.Duration, name string) (map[string]*IndexTemplate, error) {
+	var result struct {
+		IndexTemplates []struct {
+			Name          string `json:"name"`
+			IndexTemplate struct {
+				IndexTemplate
+				Template *rawTemplate `json:"template"`
+			} `json:"index_template"`
+		} `json:"index_templates"`
+	}
+
+	resp, err := c.decode(timeout, http.MethodGet, "/_index_template/"+name, "", &result)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Is(http.StatusNotFound) {
+		return map[string]*IndexTemplate{}, nil
+	}
+
+	if !resp.IsOk() {
+		return nil, resp.Error()
+	}
+
+	templates := make(map[string]*IndexTemplate, len(result.IndexTemplates))
+	for _, item := range result.IndexTemplates {
+		it := item.IndexTemplate.IndexTemplate
+		if item.IndexTemplate.Template != nil {
+			if it.Template, err = item.IndexTemplate.Template.toTemplate(); err != nil {
+				return nil, err
+			}
+		}
+		templates[item.Name] = &it
+	}
+
+	return templates, nil
+}
+
+// IndexTemplateDelete
+// link: https://www.elastic.co/guide/en/elasticsearch/reference/current/indices-delete-template.html
+func (c *Connection) IndexTemplateDelete(timeout time.Duration, name string) (ok bool, err error) {
+	resp, err := c.Delete(timeout, "/_index_template/"+name, "")
+	if err != nil {
+		return
+	}
+
+	if !resp.IsOk() {
+		return false, resp.Error()
+	}
+
+	return true, nil
+}
+
+// IndexTemplateSimulate settings, mappings and aliases which would be applied to index
+// link: https://www.elastic.co/guide/en/elasticsearch/reference/current/indices-simulate-index.html
+func (c *Connection) IndexTemplateSimulate(timeout time.Duration, index string) (*SimulatedIndex, error) {
+	var result struct {
+		Template    *rawTemplate `json:"template"`
+		Overlapping []struct {
+			Name          string   `json:"name"`
+			IndexPatterns []string `json:"index_patterns"`
+		} `json:"overlapping"`
+	}
+
+	resp, err := c.decode(timeout, http.MethodPost, "/_index_template/_simulate_index/"+index, "", &result)
+	if err != nil {
+		return nil, err
+	}
+
+	if !resp.IsOk() {
+		return nil, resp.Error()
+	}
+
+	si := &SimulatedIndex{Overlapping: result.Overlapping}
+	if result.Template != nil {
+		if si.Template, err = result.Template.toTemplate(); err != nil {
+			return nil, err
+		}
+	}
+
+	return si, nil
+}
+
+// ComponentTemplatePut create or update component template
+// link: https://www.elastic.co/guide/en/elasticsearch/reference/current/indices-component-template.html
+func (c *Connection) ComponentTemplatePut(timeout time.Duration, name string, template *ComponentTemplate) (ok bool, err error) {
+	resp, err := c.Put(timeout, "/_component_template/"+name, base.Bytes2String(template.Marshal()))
+	if err != nil {
+		return
+	}
+
+	if !resp.IsOk() {
+		return false, resp.Error()
+	}
+
+	return true, nil
+}
+
+// ComponentTemplateGet name can be wildcard pattern, empty name returns all component templates
+// link: https://www.elastic.co/guide/en/elasticsearch/reference/current/getting-component-templates.html
+func (c *Connection) ComponentTemplateGet(timeout time.Duration, name string) (map[string]*ComponentTemplate, error) {
+	var result struct {
+		ComponentTemplates []struct {
+			Name              string `json:"name"`
+			ComponentTemplate struct {
+				ComponentTemplate
+				Template *rawTemplate `json:"template"`
+			} `json:"component_template"`
+		} `json:"component_templates"`
+	}
+
+	resp, err := c.decode(timeout, http.MethodGet, "/_component_template/"+name, "", &result)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Is(http.StatusNotFound) {
+		return map[string]*ComponentTemplate{}, nil
+	}
+
+	if !resp.IsOk() {
+		return nil, resp.Error()
+	}
+
+	templates := make(map[string]*ComponentTemplate, len(result.ComponentTemplates))
+	for _, item := range result.ComponentTemplates {
+		ct := item.ComponentTemplate.ComponentTemplate
+		if item.ComponentTemplate.Template != nil {
+			if ct.Template, err = item.ComponentTemplate.Template.toTemplate(); err != nil {
+				return nil, err
+			}
+		}
+		templates[item.Name] = &ct
+	}
+
+	return templates, nil
+}
+
+// ComponentTemplateDelete
+// link: https://www.elastic.co/guide/en/elasticsearch/reference/current/indices-delete-component-template.html
+func (c *Connection) ComponentTemplateDelete(timeout time.Duration, name string) (ok bool, err error) {
+	resp, err := c.Delete(timeout, "/_component_template/"+name, "")
+	if err != nil {
+		return
+	}
+
+	if !resp.IsOk() {
+		return false, resp.Error()
+	}
+
+	return true, nil
+}
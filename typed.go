@@ -0,0 +1,72 @@
+package elastic
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/grpc-boot/elastic/results"
+
+	"github.com/grpc-boot/base"
+)
+
+// SearchAs search and decode source of hits into T
+func SearchAs[T any](timeout time.Duration, conn *Connection, q *Query) (*results.TypedSearchResult[T], error) {
+	result := &results.TypedSearchResult[T]{}
+	resp, err := conn.decode(timeout, http.MethodGet, "/"+q.index+"/_search", q.Build(), result)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.IsOk() {
+		return result, nil
+	}
+
+	return nil, resp.Error()
+}
+
+// DocsGetAs get document and decode source into T
+// link: https://www.elastic.co/guide/en/elasticsearch/reference/current/docs-get.html
+func DocsGetAs[T any](timeout time.Duration, conn *Connection, index string, id string, opts ...*DocOptions) (*results.Document[T], error) {
+	doc := &results.Document[T]{}
+	resp, err := conn.decode(timeout, http.MethodGet, withPath("/"+index+"/_doc/"+id, opts), "", doc)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.IsOk() {
+		return doc, nil
+	}
+
+	if resp.Is(http.StatusNotFound) {
+		if err = base.JsonUnmarshal(resp.Body, doc); err != nil {
+			return nil, err
+		}
+		return doc, nil
+	}
+
+	return nil, resp.Error()
+}
+
+// DocsMGetAs get documents and decode sources into T
+// link: https://www.elastic.co/guide/en/elasticsearch/reference/current/docs-multi-get.html
+func DocsMGetAs[T any](timeout time.Duration, conn *Connection, index string, idList ...string) (*results.Documents[T], error) {
+	return DocsMGetAsWithOptions[T](timeout, conn, index, idList)
+}
+
+func DocsMGetAsWithOptions[T any](timeout time.Duration, conn *Connection, index string, idList []string, opts ...*DocOptions) (*results.Documents[T], error) {
+	param, _ := base.JsonMarshal(map[string]interface{}{
+		"ids": idList,
+	})
+
+	docs := &results.Documents[T]{}
+	resp, err := conn.decode(timeout, http.MethodGet, withPath("/"+index+"/_mget", opts), base.Bytes2String(param), docs)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.IsOk() {
+		return docs, nil
+	}
+
+	return nil, resp.Error()
+}
@@ -0,0 +1,116 @@
+package elastic
+
+import (
+	"time"
+
+	"github.com/grpc-boot/elastic/results"
+
+	"github.com/grpc-boot/base"
+)
+
+const (
+	LangPainless = `painless`
+)
+
+// Script
+// link: https://www.elastic.co/guide/en/elasticsearch/reference/current/modules-scripting-using.html
+type Script struct {
+	Source string         `json:"source"`
+	Lang   string         `json:"lang,omitempty"`
+	Params base.JsonParam `json:"params,omitempty"`
+}
+
+// NewScript painless script, pass values by params instead of formatting them into source so script is compiled once
+func NewScript(source string) *Script {
+	return &Script{Source: source, Lang: LangPainless}
+}
+
+func (s *Script) WithLang(lang string) *Script {
+	s.Lang = lang
+	return s
+}
+
+func (s *Script) WithParams(params base.JsonParam) *Script {
+	s.Params = params
+	return s
+}
+
+// Update body of update api
+// link: https://www.elastic.co/guide/en/elasticsearch/reference/current/docs-update.html
+type Update struct {
+	Doc            base.JsonParam `json:"doc,omitempty"`
+	DocAsUpsert    bool           `json:"doc_as_upsert,omitempty"`
+	Upsert         base.JsonParam `json:"upsert,omitempty"`
+	Script         *Script        `json:"script,omitempty"`
+	ScriptedUpsert bool           `json:"scripted_upsert,omitempty"`
+	DetectNoop     *bool          `json:"detect_noop,omitempty"`
+}
+
+func NewUpdate() *Update {
+	return &Update{}
+}
+
+// WithDoc partial document merged into existing document
+func (u *Update) WithDoc(doc base.JsonParam) *Update {
+	u.Doc = doc
+	return u
+}
+
+// WithDocAsUpsert insert doc when document not exists
+func (u *Update) WithDocAsUpsert() *Update {
+	u.DocAsUpsert = true
+	return u
+}
+
+// WithUpsert document inserted when document not exists, script is not run on insert
+func (u *Update) WithUpsert(doc base.JsonParam) *Update {
+	u.Upsert = doc
+	return u
+}
+
+func (u *Update) WithScript(script *Script) *Update {
+	u.Script = script
+	return u
+}
+
+// WithScriptedUpsert run script on insert too, with upsert document as ctx._source
+func (u *Update) WithScriptedUpsert() *Update {
+	u.ScriptedUpsert = true
+	return u
+}
+
+// WithDetectNoop false writes document even if doc changes nothing
+func (u *Update) WithDetectNoop(detect bool) *Update {
+	u.DetectNoop = &detect
+	return u
+}
+
+func (u *Update) Marshal() []byte {
+	data, _ := base.JsonMarshal(u)
+	return data
+}
+
+// DocsUpdateWith update document with update body, result is noop when nothing changed
+// link: https://www.elastic.co/guide/en/elasticsearch/reference/current/docs-update.html
+func (c *Connection) DocsUpdateWith(timeout time.Duration, index string, id string, update *Update, opts ...*DocOptions) (*results.IndexResult, error) {
+	resp, err := c.Post(timeout, withPath("/"+index+"/_update/"+id, opts), base.Bytes2String(update.Marshal()))
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.IsOk() {
+		return resp.UnmarshalIndexResult()
+	}
+
+	return nil, resp.Error()
+}
+
+// DocsUpsert merge doc into document, insert doc when document not exists
+func (c *Connection) DocsUpsert(timeout time.Duration, index string, id string, doc base.JsonParam, opts ...*DocOptions) (*results.IndexResult, error) {
+	return c.DocsUpdateWith(timeout, index, id, NewUpdate().WithDoc(doc).WithDocAsUpsert(), opts...)
+}
+
+// DocsUpdateScript run script on document, insert upsert when document not exists and upsert is not nil
+func (c *Connection) DocsUpdateScript(timeout time.Duration, index string, id string, script *Script, upsert base.JsonParam, opts ...*DocOptions) (*results.IndexResult, error) {
+	return c.DocsUpdateWith(timeout, index, id, NewUpdate().WithScript(script).WithUpsert(upsert), opts...)
+}